@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"log/slog"
 	"net/http"
 	"os/signal"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -15,6 +17,10 @@ import (
 
 	"github.com/duynhne/cart-service/config"
 	database "github.com/duynhne/cart-service/internal/core"
+	"github.com/duynhne/cart-service/internal/core/catalog"
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/cart-service/internal/core/events"
+	"github.com/duynhne/cart-service/internal/core/inventory"
 	"github.com/duynhne/cart-service/internal/core/repository"
 	logicv1 "github.com/duynhne/cart-service/internal/logic/v1"
 	v1 "github.com/duynhne/cart-service/internal/web/v1"
@@ -30,6 +36,12 @@ func main() {
 
 	clog.Setup(cfg.Logging.Level)
 
+	moneyFormat, err := domain.ParseMoneyJSONFormat(cfg.Money.JSONFormat)
+	if err != nil {
+		panic("Configuration validation failed: " + err.Error())
+	}
+	domain.SetMoneyJSONFormat(moneyFormat)
+
 	slog.Info("Service starting",
 		"service", cfg.Service.Name,
 		"version", cfg.Service.Version,
@@ -49,16 +61,143 @@ func main() {
 	defer pool.Close()
 	slog.Info("Database connection pool established")
 
-	cartRepo := repository.NewPostgresCartRepository(pool)
-	cartService := logicv1.NewCartService(cartRepo)
+	shipping, err := logicv1.NewShippingCalculator(cfg.Shipping)
+	if err != nil {
+		slog.Error("Failed to initialize shipping calculator", "error", err)
+		return
+	}
+	slog.Info("Shipping calculator initialized", "strategy", cfg.Shipping.Strategy)
+
+	serviceOpts := []logicv1.CartServiceOption{
+		logicv1.WithShippingCalculator(shipping),
+		logicv1.WithPromotionRepository(repository.NewPostgresPromotionRepository(pool)),
+		logicv1.WithMergePolicy(domain.MergePolicy(cfg.Cart.MergePolicy)),
+	}
+	if cfg.Money.RatesFile != "" {
+		rates, err := logicv1.NewFileRateProvider(cfg.Money.RatesFile)
+		if err != nil {
+			slog.Error("Failed to load exchange rates", "error", err)
+			return
+		}
+		serviceOpts = append(serviceOpts, logicv1.WithRateProvider(rates))
+		slog.Info("Exchange rates loaded", "file", cfg.Money.RatesFile)
+	}
+
+	if cfg.Tax.RulesFile != "" {
+		taxTable, err := logicv1.LoadTaxRules(cfg.Tax.RulesFile)
+		if err != nil {
+			slog.Error("Failed to load tax rules", "error", err)
+			return
+		}
+		serviceOpts = append(serviceOpts, logicv1.WithTaxCalculator(taxTable))
+		slog.Info("Tax rules loaded", "file", cfg.Tax.RulesFile)
+	}
+
+	if cfg.Catalog.ServiceURL != "" {
+		productCatalog := catalog.NewHTTPCatalog(cfg.Catalog.ServiceURL, time.Duration(cfg.Catalog.TimeoutSecs)*time.Second)
+		serviceOpts = append(serviceOpts, logicv1.WithProductCatalog(productCatalog))
+		slog.Info("Product catalog enabled", "product_service_url", cfg.Catalog.ServiceURL)
+	} else {
+		slog.Warn("PRODUCT_SERVICE_URL is empty: product names and prices sent by clients are trusted")
+	}
+
+	// Cancelled on return, which stops background workers. Workers started through
+	// workers are also stopped and awaited by runGracefulShutdown before the
+	// database pool is closed.
+	bgCtx, cancelBackground := context.WithCancel(context.Background())
+	defer cancelBackground()
+	var workers sync.WaitGroup
+	stopWorkers := func() {
+		cancelBackground()
+		workers.Wait()
+	}
+
+	if cfg.Inventory.ServiceURL != "" {
+		inventoryClient := inventory.NewHTTPInventory(cfg.Inventory.ServiceURL, time.Duration(cfg.Inventory.TimeoutSecs)*time.Second)
+		serviceOpts = append(serviceOpts, logicv1.WithInventory(inventoryClient))
+		if cfg.Inventory.Reservations {
+			reservationRepo := repository.NewPostgresReservationRepository(pool)
+			serviceOpts = append(serviceOpts, logicv1.WithReservations(reservationRepo,
+				time.Duration(cfg.Inventory.ReservationTTLMinutes)*time.Minute))
+			workers.Go(func() { sweepReservations(bgCtx, reservationRepo) })
+		}
+		slog.Info("Inventory checks enabled",
+			"inventory_service_url", cfg.Inventory.ServiceURL,
+			"reservations", cfg.Inventory.Reservations,
+		)
+	}
+
+	serviceOpts = append(serviceOpts, logicv1.WithCheckout(repository.NewPostgresCheckoutRepository(pool),
+		time.Duration(cfg.Checkout.LockTTLMinutes)*time.Minute))
+	serviceOpts = append(serviceOpts, logicv1.WithMaxBatchOperations(cfg.Cart.BatchMaxOperations))
+	if len(cfg.Internal.Services) == 0 {
+		slog.Warn("INTERNAL_SERVICES is empty: internal API disabled, checkout locks only lapse")
+	}
+
+	cartRepo := repository.NewPostgresCartRepository(pool, cfg.Cart.MaxLines)
+	cartService := logicv1.NewCartService(cartRepo, serviceOpts...)
 	cartHandler := v1.NewCartHandler(cartService)
 
-	authClient := middleware.NewAuthClient(cfg.AuthServiceURL)
-	slog.Info("Auth client initialized", "auth_service_url", cfg.AuthServiceURL)
+	if cfg.Cart.AbandonAfterHours > 0 {
+		sweeper := logicv1.NewAbandonmentSweeper(
+			repository.NewPostgresCartLifecycleRepository(pool),
+			time.Duration(cfg.Cart.AbandonAfterHours)*time.Hour,
+			time.Duration(cfg.Cart.AbandonedRetentionDays)*24*time.Hour,
+			time.Duration(cfg.Cart.SweepIntervalSecs)*time.Second,
+		)
+		workers.Go(func() { sweeper.Run(bgCtx) })
+		slog.Info("Abandoned cart sweeper started",
+			"abandon_after_hours", cfg.Cart.AbandonAfterHours,
+			"retention_days", cfg.Cart.AbandonedRetentionDays,
+		)
+	}
+
+	authenticator := initAuthenticator(bgCtx, cfg, &workers)
+	if cfg.AuthMode == string(middleware.AuthModeDemo) {
+		slog.Warn("AUTH_MODE=demo: unauthenticated requests fall back to user 1")
+	}
+
+	cartTokenSecret := cfg.Cart.TokenSecret
+	if cartTokenSecret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			slog.Error("Failed to generate cart token secret", "error", err)
+			return
+		}
+		cartTokenSecret = string(secret)
+		slog.Warn("CART_TOKEN_SECRET not set, guest carts will not survive a restart")
+	}
+	cartTokens := middleware.NewCartTokenSigner(cartTokenSecret)
+
+	// Every event also fans out to webhook subscriptions, which the dispatcher delivers
+	webhookRepo := repository.NewPostgresWebhookRepository(pool)
+	webhookService := logicv1.NewWebhookService(webhookRepo)
+	relay := logicv1.NewOutboxRelay(
+		repository.NewPostgresOutboxRepository(pool),
+		events.Fanout{initEventPublisher(cfg), webhookService},
+		time.Duration(cfg.Events.RelayIntervalSecs)*time.Second,
+	)
+	workers.Go(func() { relay.Run(bgCtx) })
+	slog.Info("Outbox relay started", "publisher", cfg.Events.Publisher)
+
+	dispatcher := logicv1.NewWebhookDispatcher(
+		webhookRepo,
+		events.NewSignedSender(time.Duration(cfg.Webhooks.TimeoutSecs)*time.Second),
+		cfg.Webhooks.MaxAttempts,
+		time.Duration(cfg.Webhooks.DispatchIntervalSecs)*time.Second,
+	)
+	workers.Go(func() { dispatcher.Run(bgCtx) })
+	if cfg.Webhooks.AdminToken == "" {
+		slog.Info("WEBHOOK_ADMIN_TOKEN is empty: webhook admin API disabled")
+	}
+
+	idempotencyRepo := repository.NewPostgresIdempotencyRepository(pool)
+	workers.Go(func() { purgeIdempotencyKeys(bgCtx, idempotencyRepo) })
+	idempotency := middleware.Idempotency(idempotencyRepo, time.Duration(cfg.Cart.IdempotencyKeyTTLHours)*time.Hour)
 
 	var isShuttingDown atomic.Bool
-	srv := setupServer(cfg, authClient, cartHandler, &isShuttingDown)
-	runGracefulShutdown(cfg, srv, tp, pool, &isShuttingDown)
+	srv := setupServer(cfg, authenticator, cartTokens, idempotency, cartHandler, v1.NewWebhookHandler(webhookService), &isShuttingDown)
+	runGracefulShutdown(cfg, srv, tp, pool, stopWorkers, &isShuttingDown)
 }
 
 func initTracing(cfg *config.Config) interface{ Shutdown(context.Context) error } {
@@ -90,8 +229,92 @@ func initProfiling(cfg *config.Config) {
 	slog.Info("Profiling initialized", "endpoint", cfg.Profiling.Endpoint)
 }
 
-func setupServer(cfg *config.Config, authClient *middleware.AuthClient, cartHandler *v1.CartHandler, isShuttingDown *atomic.Bool) *http.Server {
-	r := gin.Default()
+// initAuthenticator selects local JWT verification or remote introspection (AUTH_VERIFICATION).
+// In JWT mode the JWKS is loaded once up front and then refreshed by a worker until
+// ctx is cancelled; a failed initial load is logged and retried on demand.
+func initAuthenticator(ctx context.Context, cfg *config.Config, workers *sync.WaitGroup) middleware.Authenticator {
+	slog.Info("Auth initialized",
+		"verification", cfg.Auth.Verification,
+		"auth_service_url", cfg.AuthServiceURL,
+		"mode", cfg.AuthMode,
+	)
+
+	if cfg.Auth.Verification == "introspection" {
+		return middleware.NewAuthClient(cfg.AuthServiceURL,
+			middleware.WithTokenCache(
+				cfg.Auth.CacheSize,
+				time.Duration(cfg.Auth.CacheTTLSecs)*time.Second,
+				time.Duration(cfg.Auth.NegativeCacheTTLSec)*time.Second,
+			),
+			middleware.WithTimeout(time.Duration(cfg.Auth.TimeoutSecs)*time.Second),
+			middleware.WithRetries(cfg.Auth.MaxRetries, 100*time.Millisecond),
+			middleware.WithCircuitBreaker(cfg.Auth.BreakerThreshold, time.Duration(cfg.Auth.BreakerCooldownSecs)*time.Second),
+		)
+	}
+
+	jwks := middleware.NewJWKSCache(cfg.Auth.JWKSURL, time.Duration(cfg.Auth.JWKSRefreshSecs)*time.Second)
+	if err := jwks.Refresh(ctx); err != nil {
+		slog.Warn("Initial JWKS fetch failed", "url", cfg.Auth.JWKSURL, "error", err)
+	}
+	workers.Go(func() { jwks.Run(ctx) })
+
+	return middleware.NewJWTVerifier(jwks, cfg.Auth.Issuer, cfg.Auth.Audience)
+}
+
+// initEventPublisher selects where cart events are published (EVENTS_PUBLISHER)
+func initEventPublisher(cfg *config.Config) domain.EventPublisher {
+	if cfg.Events.Publisher == "webhook" {
+		return events.NewWebhookPublisher(cfg.Events.WebhookURL, 0)
+	}
+	return events.LogPublisher{}
+}
+
+// purgeIdempotencyKeys deletes expired idempotency keys every hour until ctx is cancelled
+func purgeIdempotencyKeys(ctx context.Context, repo *repository.PostgresIdempotencyRepository) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := repo.DeleteExpired(ctx)
+			if err != nil {
+				slog.Warn("Failed to purge expired idempotency keys", "error", err)
+				continue
+			}
+			slog.Debug("Purged expired idempotency keys", "deleted", deleted)
+		}
+	}
+}
+
+// sweepReservations deletes expired inventory reservations every minute until ctx is cancelled
+func sweepReservations(ctx context.Context, repo *repository.PostgresReservationRepository) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := repo.DeleteExpired(ctx)
+			if err != nil {
+				slog.Warn("Failed to sweep expired inventory reservations", "error", err)
+				continue
+			}
+			slog.Debug("Swept expired inventory reservations", "deleted", deleted)
+		}
+	}
+}
+
+func setupServer(cfg *config.Config, authenticator middleware.Authenticator, cartTokens *middleware.CartTokenSigner, idempotency gin.HandlerFunc, cartHandler *v1.CartHandler, webhookHandler *v1.WebhookHandler, isShuttingDown *atomic.Bool) *http.Server {
+	r := gin.New()
+	r.Use(gin.Logger(), gin.CustomRecovery(middleware.RecoverWithProblem))
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(middleware.NoRouteProblem)
+	r.NoMethod(middleware.NoMethodProblem)
 
 	r.Use(middleware.TracingMiddleware())
 	r.Use(middleware.LoggingMiddleware())
@@ -105,20 +328,76 @@ func setupServer(cfg *config.Config, authClient *middleware.AuthClient, cartHand
 			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting_down"})
 			return
 		}
+		// Stop routing traffic here while the auth circuit breaker is open
+		if rc, ok := authenticator.(middleware.ReadinessChecker); ok && !rc.Ready() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "auth_unavailable"})
+			return
+		}
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// Cart v1 routes — all private (JWT required). Variant A edge naming.
 	privateCart := r.Group("/cart/v1/private")
-	privateCart.Use(middleware.AuthMiddleware(authClient))
+	privateCart.Use(middleware.AuthMiddleware(authenticator, cartTokens, middleware.AuthMode(cfg.AuthMode)))
+	privateCart.Use(middleware.IfMatch())
+	privateCart.Use(idempotency)
 	{
 		privateCart.GET("/cart", cartHandler.GetCart)
 		privateCart.POST("/cart", cartHandler.AddToCart)
+		privateCart.PATCH("/cart", cartHandler.UpdateCart)
 		privateCart.DELETE("/cart", cartHandler.ClearCart)
 		privateCart.GET("/cart/count", cartHandler.GetCartCount)
 		privateCart.PATCH("/cart/items/:itemId", cartHandler.UpdateCartItem)
 		privateCart.DELETE("/cart/items/:itemId", cartHandler.RemoveCartItem)
+		privateCart.POST("/cart/items/:itemId/save-for-later", cartHandler.SaveForLater)
+		privateCart.GET("/cart/saved", cartHandler.ListSaved)
+		privateCart.POST("/cart/saved/:itemId/move-to-cart", cartHandler.MoveToCart)
+		privateCart.DELETE("/cart/saved/:itemId", cartHandler.RemoveSavedItem)
+		privateCart.POST("/cart/coupons", cartHandler.ApplyCoupon)
+		privateCart.DELETE("/cart/coupons/:code", cartHandler.RemoveCoupon)
+		privateCart.POST("/cart/merge", cartHandler.MergeCart)
+		privateCart.POST("/cart/checkout", cartHandler.Checkout)
+		privateCart.POST("/cart/items\\:batch", cartHandler.BatchItems)
+
+		// Named carts and wishlists; /cart above is the user's active cart
+		privateCart.GET("/carts", cartHandler.ListCarts)
+		privateCart.POST("/carts", cartHandler.CreateCart)
+		namedCart := privateCart.Group("/carts/:cartId", v1.SelectCart)
+		namedCart.GET("", cartHandler.GetCart)
+		namedCart.PATCH("", cartHandler.UpdateCart)
+		namedCart.DELETE("", cartHandler.DeleteCart)
+		namedCart.POST("/activate", cartHandler.ActivateCart)
+		namedCart.POST("/items", cartHandler.AddToCart)
+		namedCart.DELETE("/items", cartHandler.ClearCart)
+		namedCart.PATCH("/items/:itemId", cartHandler.UpdateCartItem)
+		namedCart.DELETE("/items/:itemId", cartHandler.RemoveCartItem)
+	}
+
+	// Operator API for webhook subscriptions, only when an admin token is configured
+	if cfg.Webhooks.AdminToken != "" {
+		admin := r.Group("/cart/v1/admin", middleware.AdminAuth(cfg.Webhooks.AdminToken))
+		admin.GET("/webhooks", webhookHandler.ListWebhooks)
+		admin.POST("/webhooks", webhookHandler.CreateWebhook)
+		admin.DELETE("/webhooks/:webhookId", webhookHandler.DeleteWebhook)
+		admin.GET("/webhooks/:webhookId/deliveries", webhookHandler.ListDeliveries)
+		admin.POST("/webhooks/:webhookId/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhook)
+	}
+
+	// Service-to-service API for the order service and other backends, only when
+	// callers are configured. Each route requires its own permission.
+	if len(cfg.Internal.Services) > 0 {
+		internal := r.Group("/cart/v1/internal")
+		internal.Use(middleware.ServiceAuth(internalCallers(cfg), time.Duration(cfg.Internal.MaxSkewSecs)*time.Second))
+		internal.Use(middleware.IfMatch())
+
+		userCart := internal.Group("/users/:userId/cart", v1.ActAsUser)
+		userCart.GET("", middleware.RequireServicePermission("cart:read"), cartHandler.GetCart)
+		userCart.DELETE("", middleware.RequireServicePermission("cart:clear"), cartHandler.ClearCart)
+
+		internal.GET("/checkouts/:snapshotId", middleware.RequireServicePermission("checkout:read"), cartHandler.GetCheckout)
+		internal.POST("/checkouts/:snapshotId/confirm", middleware.RequireServicePermission("checkout:resolve"), cartHandler.ConfirmCheckout)
+		internal.POST("/checkouts/:snapshotId/cancel", middleware.RequireServicePermission("checkout:resolve"), cartHandler.CancelCheckout)
 	}
 
 	return &http.Server{
@@ -128,11 +407,21 @@ func setupServer(cfg *config.Config, authClient *middleware.AuthClient, cartHand
 	}
 }
 
+// internalCallers returns the services allowed to call the internal API
+func internalCallers(cfg *config.Config) []middleware.ServiceCaller {
+	callers := make([]middleware.ServiceCaller, 0, len(cfg.Internal.Services))
+	for _, svc := range cfg.Internal.Services {
+		callers = append(callers, middleware.ServiceCaller{Name: svc.Name, Secret: svc.Secret, Permissions: svc.Permissions})
+	}
+	return callers
+}
+
 func runGracefulShutdown(
 	cfg *config.Config,
 	srv *http.Server,
 	tp interface{ Shutdown(context.Context) error },
 	pool interface{ Close() },
+	stopWorkers func(),
 	isShuttingDown *atomic.Bool,
 ) {
 	go func() {
@@ -167,6 +456,9 @@ func runGracefulShutdown(
 		slog.Info("HTTP server shutdown complete")
 	}
 
+	stopWorkers()
+	slog.Info("Background workers stopped")
+
 	pool.Close()
 	slog.Info("Database pool closed")
 
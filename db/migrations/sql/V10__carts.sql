@@ -0,0 +1,101 @@
+-- V10__carts.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Introduce a carts header table; items and coupons reference it by cart_id
+
+-- =============================================================================
+-- CARTS
+-- =============================================================================
+-- Until now a cart existed only as cart_items rows sharing a user_id, with its
+-- version in cart_versions. The header row holds the cart-level state. It is
+-- created by the first mutation of a cart; carts without a row are empty at
+-- version 0.
+
+CREATE TABLE IF NOT EXISTS carts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(64) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    status VARCHAR(20) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'checked_out', 'abandoned')),
+    version BIGINT NOT NULL DEFAULT 0 CHECK (version >= 0),
+    notes TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_cart_owner UNIQUE (user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_carts_expires_at ON carts(expires_at) WHERE expires_at IS NOT NULL;
+
+-- One header per existing owner, keeping the version from cart_versions
+INSERT INTO carts (user_id, currency, version, created_at, updated_at)
+SELECT owners.user_id,
+       COALESCE((SELECT MIN(i.currency) FROM cart_items i WHERE i.user_id = owners.user_id), 'USD'),
+       COALESCE(v.version, 1),
+       NOW(),
+       NOW()
+FROM (
+    SELECT user_id FROM cart_items
+    UNION
+    SELECT user_id FROM cart_coupons
+    UNION
+    SELECT user_id FROM cart_versions
+) owners
+LEFT JOIN cart_versions v ON v.user_id = owners.user_id
+ON CONFLICT (user_id) DO NOTHING;
+
+-- =============================================================================
+-- CART ITEMS
+-- =============================================================================
+ALTER TABLE cart_items
+    ADD COLUMN IF NOT EXISTS cart_id BIGINT REFERENCES carts(id) ON DELETE CASCADE;
+
+UPDATE cart_items i SET cart_id = c.id FROM carts c WHERE c.user_id = i.user_id;
+
+ALTER TABLE cart_items
+    ALTER COLUMN cart_id SET NOT NULL,
+    DROP CONSTRAINT IF EXISTS unique_user_product,
+    ADD CONSTRAINT unique_cart_product UNIQUE (cart_id, product_id);
+
+DROP INDEX IF EXISTS idx_cart_items_user;
+ALTER TABLE cart_items DROP COLUMN user_id;
+
+-- =============================================================================
+-- CART COUPONS
+-- =============================================================================
+ALTER TABLE cart_coupons
+    ADD COLUMN IF NOT EXISTS cart_id BIGINT REFERENCES carts(id) ON DELETE CASCADE;
+
+UPDATE cart_coupons cc SET cart_id = c.id FROM carts c WHERE c.user_id = cc.user_id;
+
+ALTER TABLE cart_coupons
+    ALTER COLUMN cart_id SET NOT NULL,
+    DROP CONSTRAINT IF EXISTS cart_coupons_pkey,
+    ADD PRIMARY KEY (cart_id, promotion_id);
+
+ALTER TABLE cart_coupons DROP COLUMN user_id;
+
+-- The version now lives on the header
+DROP TABLE IF EXISTS cart_versions;
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON TABLE carts IS 'Cart header: one row per cart with its cart-level state';
+COMMENT ON COLUMN carts.user_id IS 'Cart owner: auth.users.id, or guest:<id> for guest carts';
+COMMENT ON COLUMN carts.currency IS 'ISO 4217 currency of every item in the cart';
+COMMENT ON COLUMN carts.status IS 'Lifecycle: active, checked_out or abandoned';
+COMMENT ON COLUMN carts.version IS 'Incremented by every cart mutation; served as the cart ETag';
+COMMENT ON COLUMN carts.notes IS 'Free-form note left by the shopper';
+COMMENT ON COLUMN carts.expires_at IS 'When the cart may be discarded; NULL keeps it indefinitely';
+COMMENT ON COLUMN cart_items.cart_id IS 'Cart the item belongs to';
+COMMENT ON COLUMN cart_coupons.cart_id IS 'Cart the coupon is applied to';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Cart headers created' as status,
+    (SELECT COUNT(*) FROM carts) as carts,
+    (SELECT COUNT(*) FROM cart_items) as cart_items,
+    (SELECT COUNT(*) FROM cart_coupons) as cart_coupons;
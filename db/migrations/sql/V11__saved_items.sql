@@ -0,0 +1,41 @@
+-- V11__saved_items.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Saved-for-later list per user
+
+-- =============================================================================
+-- SAVED ITEMS
+-- =============================================================================
+-- Items moved out of the cart without being removed. A product is either in the
+-- cart or in the saved list, never both: moves delete the source row and upsert
+-- the target in one transaction, and adding a product to the cart removes it here.
+
+CREATE TABLE IF NOT EXISTS saved_items (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(64) NOT NULL,
+    product_id INTEGER NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    product_category VARCHAR(100) NOT NULL DEFAULT '',
+    tax_class VARCHAR(32) NOT NULL DEFAULT 'standard',
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    product_price NUMERIC(15,3) NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_saved_user_product UNIQUE (user_id, product_id)
+);
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON TABLE saved_items IS 'Saved-for-later items, kept out of the cart and its totals';
+COMMENT ON COLUMN saved_items.user_id IS 'List owner: auth.users.id, or guest:<id> for guest carts';
+COMMENT ON COLUMN saved_items.product_price IS 'Product price at time of adding to cart';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Saved items created' as status,
+    COUNT(*) as saved_items
+FROM saved_items;
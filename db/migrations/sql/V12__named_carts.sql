@@ -0,0 +1,40 @@
+-- V12__named_carts.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Several named carts and wishlists per user, with one active cart
+
+-- =============================================================================
+-- NAMED CARTS
+-- =============================================================================
+-- A user may own any number of carts and wishlists. Exactly one of them is
+-- active: the cart served by the /cart routes, created by its first mutation
+-- when the user has none. The partial unique index is the active cart pointer.
+
+ALTER TABLE carts
+    ADD COLUMN IF NOT EXISTS name VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'cart'
+        CHECK (kind IN ('cart', 'wishlist')),
+    ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    DROP CONSTRAINT IF EXISTS unique_cart_owner;
+
+-- Every existing cart is its owner's only cart
+UPDATE carts SET is_active = TRUE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_carts_active_owner ON carts(user_id) WHERE is_active;
+CREATE INDEX IF NOT EXISTS idx_carts_user ON carts(user_id);
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON COLUMN carts.name IS 'Name chosen by the owner; empty for the default cart';
+COMMENT ON COLUMN carts.kind IS 'cart or wishlist';
+COMMENT ON COLUMN carts.is_active IS 'The owner''s active cart, served by the /cart routes; at most one per owner';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Named carts enabled' as status,
+    COUNT(*) as carts,
+    COUNT(*) FILTER (WHERE is_active) as active_carts
+FROM carts;
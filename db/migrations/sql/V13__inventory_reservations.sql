@@ -0,0 +1,40 @@
+-- V13__inventory_reservations.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Soft inventory reservations held by cart items
+
+-- =============================================================================
+-- INVENTORY RESERVATIONS
+-- =============================================================================
+-- One row per cart item, holding its quantity until expires_at. Every cart
+-- change rewrites the cart's rows with a fresh expiry; removing an item or
+-- clearing the cart deletes them through the cascade, and a background sweeper
+-- deletes the expired ones. Expired rows are ignored by stock checks.
+
+CREATE TABLE IF NOT EXISTS inventory_reservations (
+    cart_item_id BIGINT PRIMARY KEY REFERENCES cart_items(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_reservations_product ON inventory_reservations(product_id, expires_at);
+CREATE INDEX IF NOT EXISTS idx_inventory_reservations_expires_at ON inventory_reservations(expires_at);
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON TABLE inventory_reservations IS 'Stock held by cart items, subtracted from the stock other carts see';
+COMMENT ON COLUMN inventory_reservations.product_id IS 'Product of the cart item, denormalized for stock lookups';
+COMMENT ON COLUMN inventory_reservations.quantity IS 'Quantity held: the cart item quantity at the last cart change';
+COMMENT ON COLUMN inventory_reservations.expires_at IS 'When the hold lapses unless the cart changes again';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Inventory reservations created' as status,
+    COUNT(*) as inventory_reservations
+FROM inventory_reservations;
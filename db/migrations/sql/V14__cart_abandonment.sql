@@ -0,0 +1,33 @@
+-- V14__cart_abandonment.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Support the abandoned cart sweeper
+
+-- =============================================================================
+-- CARTS
+-- =============================================================================
+-- The sweeper marks active carts abandoned once carts.updated_at, bumped by
+-- every cart change, is older than the idle period, and gives them an
+-- expires_at. Any later change makes the cart active again and clears the
+-- expiry; carts still abandoned at expires_at are deleted
+-- (idx_carts_expires_at).
+
+CREATE INDEX IF NOT EXISTS idx_carts_idle ON carts(updated_at)
+    WHERE status = 'active' AND kind = 'cart';
+
+-- Idle detection reads the cart header; item timestamps were never queried
+DROP INDEX IF EXISTS idx_cart_items_updated_at;
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON COLUMN carts.updated_at IS 'Last cart change; carts idle for longer than CART_ABANDON_AFTER_HOURS are abandoned';
+COMMENT ON COLUMN carts.expires_at IS 'When the cart is deleted; set when it is abandoned, cleared when it is used again';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Cart abandonment index created' as status,
+    (SELECT COUNT(*) FROM carts WHERE status = 'active') as active_carts,
+    (SELECT COUNT(*) FROM carts WHERE status = 'abandoned') as abandoned_carts;
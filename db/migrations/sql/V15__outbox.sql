@@ -0,0 +1,46 @@
+-- V15__outbox.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Transactional outbox for cart domain events
+
+-- =============================================================================
+-- OUTBOX
+-- =============================================================================
+-- Cart mutations insert their events here in the same transaction as the change,
+-- so an event exists exactly when the change was committed. The relay claims
+-- due rows by pushing next_attempt_at forward (a lease), publishes them and sets
+-- published_at; a failed or interrupted delivery is retried once the lease or
+-- the backoff has passed. Published rows are deleted after a retention period.
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL,
+    cart_id BIGINT NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    published_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(next_attempt_at, id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_published_at ON outbox(published_at) WHERE published_at IS NOT NULL;
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON TABLE outbox IS 'Cart domain events awaiting or past delivery by the outbox relay';
+COMMENT ON COLUMN outbox.cart_id IS 'Cart the event belongs to; no foreign key, events outlive deleted carts';
+COMMENT ON COLUMN outbox.payload IS 'JSON encoded event data, shaped by event_type';
+COMMENT ON COLUMN outbox.next_attempt_at IS 'Earliest next delivery attempt: lease end while claimed, backoff end after a failure';
+COMMENT ON COLUMN outbox.published_at IS 'When the event was delivered; NULL while pending';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Outbox created' as status,
+    COUNT(*) as outbox_events
+FROM outbox;
@@ -0,0 +1,71 @@
+-- V16__webhooks.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Webhook subscriptions for cart events and their delivery log
+
+-- =============================================================================
+-- WEBHOOK SUBSCRIPTIONS
+-- =============================================================================
+-- Registered through the admin API. A subscription receives every cart event
+-- whose type is in event_types, or every event when event_types is empty.
+-- The secret signs deliveries and is kept in plain text because signing needs it.
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    secret VARCHAR(128) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- =============================================================================
+-- WEBHOOK DELIVERIES
+-- =============================================================================
+-- One row per (subscription, event), created when the outbox relay publishes
+-- the event, so a redelivered outbox event is not sent twice. The dispatcher
+-- claims due pending rows by pushing next_attempt_at forward (a lease), like the
+-- outbox relay. Rows that run out of attempts become 'dead' and stay as the
+-- dead letter log until they are redelivered or their subscription is deleted.
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, id DESC);
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON TABLE webhook_subscriptions IS 'Endpoints that receive signed cart events';
+COMMENT ON COLUMN webhook_subscriptions.event_types IS 'Event types delivered to the endpoint; empty means all';
+COMMENT ON COLUMN webhook_subscriptions.secret IS 'HMAC-SHA256 key for the X-Webhook-Signature header';
+COMMENT ON TABLE webhook_deliveries IS 'Delivery log of cart events to webhook subscriptions';
+COMMENT ON COLUMN webhook_deliveries.event_id IS 'outbox.id of the event; no foreign key, deliveries outlive purged outbox rows';
+COMMENT ON COLUMN webhook_deliveries.payload IS 'Event as sent in the request body';
+COMMENT ON COLUMN webhook_deliveries.status IS 'pending: awaiting (re)delivery; delivered: endpoint returned 2xx; dead: gave up after the maximum attempts';
+COMMENT ON COLUMN webhook_deliveries.next_attempt_at IS 'Earliest next delivery attempt: lease end while claimed, backoff end after a failure';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Webhook tables created' as status,
+    (SELECT COUNT(*) FROM webhook_subscriptions) as subscriptions,
+    (SELECT COUNT(*) FROM webhook_deliveries) as deliveries;
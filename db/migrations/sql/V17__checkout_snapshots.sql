@@ -0,0 +1,95 @@
+-- V17__checkout_snapshots.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Immutable checkout snapshots and the cart lock held while an order is placed
+
+-- =============================================================================
+-- CART LOCK
+-- =============================================================================
+-- Checkout sets status = 'checking_out', locked_until and checkout_snapshot_id.
+-- Mutations are refused while the lock holds. The order service confirms or
+-- cancels the snapshot to release it; otherwise the next mutation after
+-- locked_until reopens the cart.
+
+ALTER TABLE carts DROP CONSTRAINT IF EXISTS carts_status_check;
+ALTER TABLE carts ADD CONSTRAINT carts_status_check
+    CHECK (status IN ('active', 'checking_out', 'checked_out', 'abandoned'));
+
+ALTER TABLE carts
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS checkout_snapshot_id BIGINT NULL;
+
+-- =============================================================================
+-- CART SNAPSHOTS
+-- =============================================================================
+-- The priced cart as it was at checkout. Rows are written once; only status,
+-- order_id and resolved_at change when the order service answers. cart_id has no
+-- foreign key: snapshots are the order's record and outlive the cart.
+
+CREATE TABLE IF NOT EXISTS cart_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    cart_id BIGINT NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    cart_version BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'confirmed', 'cancelled')),
+    order_id VARCHAR(64),
+    currency CHAR(3) NOT NULL,
+    subtotal NUMERIC(15,3) NOT NULL,
+    discount NUMERIC(15,3) NOT NULL,
+    shipping NUMERIC(15,3) NOT NULL,
+    tax NUMERIC(15,3) NOT NULL,
+    tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    total NUMERIC(15,3) NOT NULL,
+    item_count INTEGER NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cart_snapshots_cart ON cart_snapshots(cart_id);
+
+CREATE TABLE IF NOT EXISTS cart_snapshot_items (
+    snapshot_id BIGINT NOT NULL REFERENCES cart_snapshots(id) ON DELETE CASCADE,
+    item_id BIGINT NOT NULL,
+    product_id INTEGER NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    product_category VARCHAR(100) NOT NULL DEFAULT '',
+    tax_class VARCHAR(32) NOT NULL,
+    product_price NUMERIC(15,3) NOT NULL,
+    quantity INTEGER NOT NULL,
+    subtotal NUMERIC(15,3) NOT NULL,
+    tax_rate VARCHAR(16) NOT NULL,
+    tax NUMERIC(15,3) NOT NULL,
+    PRIMARY KEY (snapshot_id, item_id)
+);
+
+CREATE TABLE IF NOT EXISTS cart_snapshot_discounts (
+    snapshot_id BIGINT NOT NULL REFERENCES cart_snapshots(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    code VARCHAR(64) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    scope VARCHAR(16) NOT NULL,
+    item_id VARCHAR(32) NOT NULL DEFAULT '',
+    product_id VARCHAR(32) NOT NULL DEFAULT '',
+    amount NUMERIC(15,3) NOT NULL,
+    PRIMARY KEY (snapshot_id, position)
+);
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON COLUMN carts.locked_until IS 'End of the checkout lock; NULL when the cart is not being checked out';
+COMMENT ON COLUMN carts.checkout_snapshot_id IS 'Snapshot holding the checkout lock';
+COMMENT ON TABLE cart_snapshots IS 'Immutable priced carts handed to the order service at checkout';
+COMMENT ON COLUMN cart_snapshots.cart_version IS 'Cart version the snapshot was taken from';
+COMMENT ON COLUMN cart_snapshots.status IS 'pending: cart locked; confirmed: order placed and cart emptied; cancelled: cart unlocked';
+COMMENT ON COLUMN cart_snapshots.expires_at IS 'When the cart lock lapses if the snapshot is still pending';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Checkout snapshots created' as status,
+    (SELECT COUNT(*) FROM cart_snapshots) as snapshots,
+    (SELECT COUNT(*) FROM carts WHERE locked_until IS NOT NULL) as locked_carts;
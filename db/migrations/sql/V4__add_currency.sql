@@ -0,0 +1,39 @@
+-- V4__add_currency.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Capture the ISO 4217 currency of each cart item and store prices
+--          with the three decimal places of currencies such as KWD
+
+-- =============================================================================
+-- ADD CURRENCY COLUMN
+-- =============================================================================
+-- Existing rows were all priced in USD before multi-currency support.
+-- A cart's currency is the currency of its items; the service rejects
+-- adding an item in a different currency to a non-empty cart.
+
+ALTER TABLE cart_items
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- =============================================================================
+-- WIDEN MONEY COLUMNS
+-- =============================================================================
+-- Some ISO 4217 currencies (BHD, JOD, KWD, OMR, ...) have three decimal places.
+-- With a scale of 2 Postgres would silently round 1.234 KWD to 1.23, so every
+-- money column is NUMERIC(15,3). Later tables copy the type.
+
+ALTER TABLE cart_items
+    ALTER COLUMN product_price TYPE NUMERIC(15,3);
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON COLUMN cart_items.currency IS 'ISO 4217 currency of product_price at time of adding to cart';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Currency column added' as status,
+    COUNT(*) as total_items,
+    COUNT(DISTINCT currency) as currencies
+FROM cart_items;
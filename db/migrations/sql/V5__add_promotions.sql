@@ -0,0 +1,76 @@
+-- V5__add_promotions.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Coupon-activated promotions and the coupons attached to each cart
+
+-- =============================================================================
+-- PRODUCT CATEGORY ON CART ITEMS
+-- =============================================================================
+-- Captured when adding to cart so category-targeted promotions can be
+-- evaluated without calling the product service.
+
+ALTER TABLE cart_items
+    ADD COLUMN IF NOT EXISTS product_category VARCHAR(100) NOT NULL DEFAULT '';
+
+-- =============================================================================
+-- PROMOTIONS TABLE
+-- =============================================================================
+-- One row per coupon code. Rule columns are used depending on type:
+--   percent_off  -> percent_off
+--   fixed_off    -> amount_off
+--   buy_x_get_y  -> buy_quantity, get_quantity
+-- Empty product_ids and categories mean the promotion targets the whole cart.
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(64) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    type VARCHAR(20) NOT NULL CHECK (type IN ('percent_off', 'fixed_off', 'buy_x_get_y')),
+    percent_off INTEGER NOT NULL DEFAULT 0 CHECK (percent_off BETWEEN 0 AND 100),
+    amount_off NUMERIC(15,3) NOT NULL DEFAULT 0.00 CHECK (amount_off >= 0),
+    buy_quantity INTEGER NOT NULL DEFAULT 0 CHECK (buy_quantity >= 0),
+    get_quantity INTEGER NOT NULL DEFAULT 0 CHECK (get_quantity >= 0),
+    currency CHAR(3) NOT NULL DEFAULT 'USD',  -- Currency of amount_off and min_subtotal
+    product_ids TEXT[] NOT NULL DEFAULT '{}',
+    categories TEXT[] NOT NULL DEFAULT '{}',
+    min_subtotal NUMERIC(15,3) NOT NULL DEFAULT 0.00 CHECK (min_subtotal >= 0),
+    usage_limit INTEGER NOT NULL DEFAULT 0 CHECK (usage_limit >= 0),  -- 0 = unlimited
+    usage_count INTEGER NOT NULL DEFAULT 0 CHECK (usage_count >= 0),
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promotions_code ON promotions(UPPER(code));
+
+-- =============================================================================
+-- CART COUPONS TABLE
+-- =============================================================================
+-- Coupons a user has applied to their cart. Eligibility is re-evaluated on
+-- every read, so a coupon stays attached while the cart is temporarily
+-- ineligible (e.g. below min_subtotal).
+
+CREATE TABLE IF NOT EXISTS cart_coupons (
+    user_id INTEGER NOT NULL,
+    promotion_id INTEGER NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, promotion_id)
+);
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON TABLE promotions IS 'Coupon-activated discount rules';
+COMMENT ON COLUMN promotions.usage_count IS 'Redemptions by placed orders; each cart holding the coupon counts as one more use';
+COMMENT ON TABLE cart_coupons IS 'Coupons attached to a user cart';
+COMMENT ON COLUMN cart_items.product_category IS 'Product category at time of adding to cart';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Promotions tables created' as status,
+    (SELECT COUNT(*) FROM promotions) as promotions,
+    (SELECT COUNT(*) FROM cart_coupons) as cart_coupons;
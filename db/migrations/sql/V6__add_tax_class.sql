@@ -0,0 +1,27 @@
+-- V6__add_tax_class.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Capture the product tax class used to look up tax rates
+
+-- =============================================================================
+-- ADD TAX CLASS COLUMN
+-- =============================================================================
+-- Tax classes (e.g. standard, reduced, grocery) are keys of the rate table
+-- loaded from TAX_RULES_FILE. Existing rows use the standard class.
+
+ALTER TABLE cart_items
+    ADD COLUMN IF NOT EXISTS tax_class VARCHAR(32) NOT NULL DEFAULT 'standard';
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON COLUMN cart_items.tax_class IS 'Product tax class at time of adding to cart';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Tax class column added' as status,
+    COUNT(*) as total_items,
+    COUNT(DISTINCT tax_class) as tax_classes
+FROM cart_items;
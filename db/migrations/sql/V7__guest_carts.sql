@@ -0,0 +1,32 @@
+-- V7__guest_carts.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Store guest (anonymous) carts alongside user carts
+
+-- =============================================================================
+-- WIDEN CART OWNER COLUMNS
+-- =============================================================================
+-- Guest carts are keyed by "guest:<32 hex chars>" taken from the signed cart
+-- token, so the owner columns can no longer be integers. Existing user IDs are
+-- kept as their decimal text.
+
+ALTER TABLE cart_items
+    ALTER COLUMN user_id TYPE VARCHAR(64) USING user_id::text;
+
+ALTER TABLE cart_coupons
+    ALTER COLUMN user_id TYPE VARCHAR(64) USING user_id::text;
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON COLUMN cart_items.user_id IS 'Cart owner: auth.users.id, or guest:<id> for guest carts';
+COMMENT ON COLUMN cart_coupons.user_id IS 'Cart owner: auth.users.id, or guest:<id> for guest carts';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Guest carts enabled' as status,
+    COUNT(*) FILTER (WHERE user_id LIKE 'guest:%') as guest_items,
+    COUNT(*) FILTER (WHERE user_id NOT LIKE 'guest:%') as user_items
+FROM cart_items;
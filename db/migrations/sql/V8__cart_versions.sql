@@ -0,0 +1,37 @@
+-- V8__cart_versions.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Track a version per cart for optimistic concurrency (ETag / If-Match)
+
+-- =============================================================================
+-- CART VERSIONS
+-- =============================================================================
+-- Every mutation of a cart (items or coupons) increments its version in the
+-- same transaction. The version is exposed as the ETag of GET /cart and checked
+-- against If-Match on mutating routes. Carts without a row are at version 0.
+
+CREATE TABLE IF NOT EXISTS cart_versions (
+    user_id VARCHAR(64) PRIMARY KEY,
+    version BIGINT NOT NULL DEFAULT 0 CHECK (version >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Existing carts start at version 1 so their first ETag differs from an empty cart's
+INSERT INTO cart_versions (user_id, version, updated_at)
+SELECT DISTINCT user_id, 1, NOW() FROM cart_items
+ON CONFLICT (user_id) DO NOTHING;
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON TABLE cart_versions IS 'Optimistic concurrency version per cart owner';
+COMMENT ON COLUMN cart_versions.user_id IS 'Cart owner: auth.users.id, or guest:<id> for guest carts';
+COMMENT ON COLUMN cart_versions.version IS 'Incremented by every cart mutation; served as the cart ETag';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Cart versions created' as status,
+    COUNT(*) as versioned_carts
+FROM cart_versions;
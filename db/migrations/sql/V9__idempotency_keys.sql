@@ -0,0 +1,42 @@
+-- V9__idempotency_keys.sql
+-- Cart Database Schema Update
+-- Last Updated: 2026-10-16
+-- Purpose: Store responses of cart mutations sent with an Idempotency-Key header
+
+-- =============================================================================
+-- IDEMPOTENCY KEYS
+-- =============================================================================
+-- A retried POST/PATCH/DELETE with the same key replays the stored response
+-- instead of running the mutation again. Keys are scoped to the cart owner.
+-- status_code is NULL while the original request is still being processed.
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    owner_id VARCHAR(64) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    status_code INTEGER,
+    response_headers JSONB,
+    response_body BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (owner_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- =============================================================================
+-- COMMENTS
+-- =============================================================================
+COMMENT ON TABLE idempotency_keys IS 'Stored responses of cart mutations keyed by Idempotency-Key';
+COMMENT ON COLUMN idempotency_keys.owner_id IS 'Cart owner: auth.users.id, or guest:<id> for guest carts';
+COMMENT ON COLUMN idempotency_keys.fingerprint IS 'SHA-256 hex of method, path and body; reuse with another request is rejected';
+COMMENT ON COLUMN idempotency_keys.status_code IS 'Response status, NULL while the original request is in progress';
+COMMENT ON COLUMN idempotency_keys.expires_at IS 'Key can be reused with a new request after this time';
+
+-- =============================================================================
+-- VERIFICATION
+-- =============================================================================
+SELECT
+    'Idempotency keys table created' as status,
+    COUNT(*) as stored_keys
+FROM idempotency_keys;
@@ -0,0 +1,129 @@
+// Package catalog provides domain.ProductCatalog implementations: a client for
+// the product service and an in-memory catalog for tests and local development.
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultTimeout = 2 * time.Second
+	// maxConcurrentLookups bounds the product service calls made by one GetProducts
+	maxConcurrentLookups = 8
+)
+
+// HTTPCatalog implements domain.ProductCatalog against the product service
+// (GET /product/v1/public/products/:id)
+type HTTPCatalog struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPCatalog creates a product service client; timeout bounds each call
+// and defaults to 2s when zero
+func NewHTTPCatalog(baseURL string, timeout time.Duration) *HTTPCatalog {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &HTTPCatalog{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// productResponse is the product service representation of a product
+type productResponse struct {
+	Name      string          `json:"name"`
+	Category  string          `json:"category"`
+	TaxClass  string          `json:"tax_class"`
+	Price     json.RawMessage `json:"price"` // JSON number or decimal string
+	Currency  string          `json:"currency"`
+	Available *bool           `json:"available"` // Absent means available
+}
+
+// GetProduct fetches one product. Unreachable service, non-2xx responses other
+// than 404 and malformed bodies wrap domain.ErrCatalogUnavailable.
+func (c *HTTPCatalog) GetProduct(ctx context.Context, productID string) (*domain.Product, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.baseURL+"/product/v1/public/products/"+url.PathEscape(productID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req) // #nosec G704
+	if err != nil {
+		return nil, fmt.Errorf("%w: request product service: %w", domain.ErrCatalogUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, domain.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%w: product service returned %d: %s", domain.ErrCatalogUnavailable, resp.StatusCode, body)
+	}
+
+	var body productResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: decode product: %w", domain.ErrCatalogUnavailable, err)
+	}
+
+	currency := strings.ToUpper(body.Currency)
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	price, err := domain.ParseMoney(strings.Trim(string(body.Price), `"`), currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: product %s price: %w", domain.ErrCatalogUnavailable, productID, err)
+	}
+
+	return &domain.Product{
+		ID:        productID,
+		Name:      body.Name,
+		Category:  body.Category,
+		TaxClass:  body.TaxClass,
+		Price:     price,
+		Available: body.Available == nil || *body.Available,
+	}, nil
+}
+
+// GetProducts fetches products concurrently, a few at a time
+func (c *HTTPCatalog) GetProducts(ctx context.Context, productIDs []string) (map[string]domain.Product, error) {
+	var mu sync.Mutex
+	products := make(map[string]domain.Product, len(productIDs))
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentLookups)
+	for _, id := range productIDs {
+		g.Go(func() error {
+			p, err := c.GetProduct(ctx, id)
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			products[id] = *p
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
@@ -0,0 +1,52 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+func TestHTTPCatalogGetProducts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/product/v1/public/products/1":
+			w.Write([]byte(`{"name":"Keyboard","category":"electronics","price":"49.99","currency":"usd"}`))
+		case "/product/v1/public/products/2":
+			w.Write([]byte(`{"name":"Mouse","price":19.5,"available":false}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	products, err := NewHTTPCatalog(srv.URL+"/", time.Second).GetProducts(context.Background(), []string{"1", "2", "3"})
+	if err != nil {
+		t.Fatalf("GetProducts() error = %v", err)
+	}
+	if p := products["1"]; p.Name != "Keyboard" || p.Price != domain.NewMoney(4999, "USD") || !p.Available {
+		t.Errorf("product 1 = %+v, want Keyboard at 49.99 USD, available", p)
+	}
+	if p := products["2"]; p.Price != domain.NewMoney(1950, domain.DefaultCurrency) || p.Available {
+		t.Errorf("product 2 = %+v, want 19.50, unavailable", p)
+	}
+	if _, ok := products["3"]; ok {
+		t.Error("unknown product 3 should be omitted")
+	}
+}
+
+func TestHTTPCatalogUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, err := NewHTTPCatalog(srv.URL, time.Second).GetProduct(context.Background(), "1")
+	if !errors.Is(err, domain.ErrCatalogUnavailable) {
+		t.Errorf("GetProduct() error = %v, want ErrCatalogUnavailable", err)
+	}
+}
@@ -0,0 +1,54 @@
+package catalog
+
+import (
+	"context"
+	"sync"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+// MemoryCatalog is an in-memory domain.ProductCatalog for tests and local development
+type MemoryCatalog struct {
+	mu       sync.RWMutex
+	products map[string]domain.Product
+}
+
+// NewMemoryCatalog creates a catalog holding products
+func NewMemoryCatalog(products ...domain.Product) *MemoryCatalog {
+	c := &MemoryCatalog{products: make(map[string]domain.Product, len(products))}
+	for _, p := range products {
+		c.products[p.ID] = p
+	}
+	return c
+}
+
+// Put adds or replaces a product
+func (c *MemoryCatalog) Put(p domain.Product) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.products[p.ID] = p
+}
+
+// GetProduct returns the product or domain.ErrNotFound
+func (c *MemoryCatalog) GetProduct(_ context.Context, productID string) (*domain.Product, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	p, ok := c.products[productID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &p, nil
+}
+
+// GetProducts returns the known products among productIDs
+func (c *MemoryCatalog) GetProducts(_ context.Context, productIDs []string) (map[string]domain.Product, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	products := make(map[string]domain.Product, len(productIDs))
+	for _, id := range productIDs {
+		if p, ok := c.products[id]; ok {
+			products[id] = p
+		}
+	}
+	return products, nil
+}
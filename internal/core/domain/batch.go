@@ -0,0 +1,67 @@
+package domain
+
+// BatchOpType is the kind of one operation in a batch of item changes
+type BatchOpType string
+
+const (
+	BatchAdd    BatchOpType = "add"    // Add a product, like POST /cart
+	BatchUpdate BatchOpType = "update" // Set the quantity of a line, like PATCH /cart/items/:id
+	BatchRemove BatchOpType = "remove" // Remove a line, like DELETE /cart/items/:id
+)
+
+// BatchMode decides what happens to a batch when one of its operations fails
+type BatchMode string
+
+const (
+	BatchAtomic     BatchMode = "atomic"      // Any failure leaves the cart unchanged
+	BatchBestEffort BatchMode = "best_effort" // Failed operations are skipped, the others applied
+)
+
+// BatchOpStatus is the outcome of one operation of a batch
+type BatchOpStatus string
+
+const (
+	BatchOpApplied    BatchOpStatus = "applied"
+	BatchOpFailed     BatchOpStatus = "failed"
+	BatchOpNotApplied BatchOpStatus = "not_applied" // Valid, but an atomic batch failed elsewhere
+)
+
+// BatchItemsRequest is a list of item changes applied to the cart in order, in
+// one transaction. Mode defaults to atomic.
+type BatchItemsRequest struct {
+	Mode       BatchMode        `json:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Operations []BatchOperation `json:"operations" binding:"required,min=1,dive"`
+}
+
+// BatchOperation is one change of a batch. Add operations carry the product in
+// Item; update and remove operations name the cart line in ItemID.
+type BatchOperation struct {
+	Op       BatchOpType       `json:"op" binding:"required,oneof=add update remove"`
+	Item     *AddToCartRequest `json:"item" binding:"required_if=Op add,omitempty"`
+	ItemID   string            `json:"item_id" binding:"required_unless=Op add"`
+	Quantity int               `json:"quantity"` // New line quantity for update
+}
+
+// BatchOp is a validated batch operation as applied by the repository
+type BatchOp struct {
+	Type     BatchOpType
+	Item     *CartItem // Item to add; set to the stored line once applied
+	ItemID   string
+	Quantity int
+}
+
+// BatchOpResult is the outcome of one operation of a batch
+type BatchOpResult struct {
+	Op     BatchOpType
+	Status BatchOpStatus
+	Item   *CartItem // The stored line after an applied add
+	Err    error     // Why the operation failed
+}
+
+// BatchItemsResult is the outcome of a batch: one result per operation, in
+// order, and the cart afterwards
+type BatchItemsResult struct {
+	Applied bool // Whether any operation changed the cart
+	Results []BatchOpResult
+	Cart    *Cart
+}
@@ -1,29 +1,205 @@
 package domain
 
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// guestOwnerPrefix marks cart owner IDs that belong to anonymous visitors
+const guestOwnerPrefix = "guest:"
+
+// GuestOwnerID returns the cart owner ID for a guest cart token ID
+func GuestOwnerID(guestID string) string {
+	return guestOwnerPrefix + guestID
+}
+
+// IsGuestOwner reports whether a cart owner ID belongs to a guest cart
+func IsGuestOwner(ownerID string) bool {
+	return strings.HasPrefix(ownerID, guestOwnerPrefix)
+}
+
+// MergePolicy decides the quantity of a product present in both the guest and the user cart
+type MergePolicy string
+
+const (
+	MergeSum      MergePolicy = "sum"       // Add both quantities
+	MergeMax      MergePolicy = "max"       // Keep the larger quantity
+	MergeKeepUser MergePolicy = "keep_user" // Keep the user's quantity
+)
+
+// CartStatus is the lifecycle state of a cart
+type CartStatus string
+
+const (
+	CartActive      CartStatus = "active"
+	CartCheckingOut CartStatus = "checking_out" // Locked by a pending checkout snapshot
+	CartCheckedOut  CartStatus = "checked_out"
+	CartAbandoned   CartStatus = "abandoned"
+)
+
+// CartKind distinguishes carts meant for checkout from wishlists
+type CartKind string
+
+const (
+	KindCart     CartKind = "cart"
+	KindWishlist CartKind = "wishlist"
+)
+
+// ItemList names the list a cart item lives in
+type ItemList string
+
+const (
+	ListCart  ItemList = "cart"  // The cart itself
+	ListSaved ItemList = "saved" // Saved for later, outside the cart totals
+)
+
 // Cart represents a shopping cart aggregate
 type Cart struct {
-	UserID    string     `json:"user_id"`
-	Items     []CartItem `json:"items"`
-	Subtotal  float64    `json:"subtotal"`
-	Shipping  float64    `json:"shipping"`
-	Total     float64    `json:"total"`
+	ID          string              `json:"id,omitempty"` // Empty until the first mutation creates the cart
+	UserID      string              `json:"user_id"`
+	Name        string              `json:"name,omitempty"`
+	Kind        CartKind            `json:"kind"`
+	Active      bool                `json:"active"`  // The cart served by the /cart routes
+	Version     int64               `json:"version"` // Incremented by every mutation; served as the ETag
+	Status      CartStatus          `json:"status"`
+	Notes       string              `json:"notes,omitempty"`
+	ExpiresAt   *time.Time          `json:"expires_at,omitempty"`
+	LockedUntil *time.Time          `json:"locked_until,omitempty"` // End of the checkout lock while checking out
+	Currency    string              `json:"currency"`
+	Items       []CartItem          `json:"items"`
+	Subtotal    Money               `json:"subtotal"`
+	Discount    Money               `json:"discount"` // Sum of Discounts
+	Discounts   []AppliedDiscount   `json:"discounts"`
+	Shipping    Money               `json:"shipping"`
+	Tax         Money               `json:"tax"`
+	TaxIncluded bool                `json:"tax_inclusive"` // Item prices already include Tax
+	Total       Money               `json:"total"`
+	ItemCount   int                 `json:"item_count"`
+	Conversion  *CurrencyConversion `json:"conversion,omitempty"`
+}
+
+// CartSummary describes one of a user's carts without its items
+type CartSummary struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Kind      CartKind   `json:"kind"`
+	Active    bool       `json:"active"`
+	Status    CartStatus `json:"status"`
+	Version   int64      `json:"version"`
+	Currency  string     `json:"currency"`
 	ItemCount int        `json:"item_count"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// CartUpdate holds the cart-level fields to change; nil fields are left as they are
+type CartUpdate struct {
+	Name  *string
+	Notes *string
+}
+
+// CurrencyConversion holds cart totals converted to a display currency
+// together with the exchange rate that was used
+type CurrencyConversion struct {
+	Currency string    `json:"currency"`
+	Rate     string    `json:"rate"`
+	RateAsOf time.Time `json:"rate_as_of"`
+	Subtotal Money     `json:"subtotal"`
+	Discount Money     `json:"discount"`
+	Shipping Money     `json:"shipping"`
+	Tax      Money     `json:"tax"`
+	Total    Money     `json:"total"`
 }
 
 // CartItem represents an item in the cart
 type CartItem struct {
-	ID           string  `json:"id"`
-	ProductID    string  `json:"product_id"`
-	ProductName  string  `json:"product_name"`
-	ProductPrice float64 `json:"product_price"`
-	Quantity     int     `json:"quantity"`
-	Subtotal     float64 `json:"subtotal"`
+	ID              string `json:"id"`
+	ProductID       string `json:"product_id"`
+	ProductName     string `json:"product_name"`
+	ProductCategory string `json:"product_category,omitempty"`
+	TaxClass        string `json:"tax_class"`
+	ProductPrice    Money  `json:"product_price"`
+	Quantity        int    `json:"quantity"`
+	Subtotal        Money  `json:"subtotal"`
+	TaxRate         string `json:"tax_rate"` // Percentage applied, e.g. "7.25"
+	Tax             Money  `json:"tax"`
+
+	// Set by GetCart when a product catalog is configured: ProductPrice is then
+	// the current catalog price and PreviousPrice the price when it was added
+	PriceChanged  bool   `json:"price_changed"`
+	PreviousPrice *Money `json:"previous_price,omitempty"`
+	Unavailable   bool   `json:"unavailable,omitempty"` // No longer orderable or gone from the catalog
+
+	// Set by GetCart when an inventory client is configured
+	StockStatus StockStatus `json:"stock_status,omitempty"`
 }
 
-// AddToCartRequest represents a request to add an item to cart
+// GetCartRequest holds the optional query parameters for retrieving a cart
+type GetCartRequest struct {
+	Region   string `form:"region"`                                       // Shipping region used to pick the shipping rule
+	Currency string `form:"currency" binding:"omitempty,iso4217"`         // Display currency for converted totals
+	Country  string `form:"country" binding:"omitempty,iso3166_1_alpha2"` // Tax jurisdiction country
+	State    string `form:"state" binding:"omitempty,max=3"`              // Tax jurisdiction subdivision, e.g. "CA"
+}
+
+// AddToCartRequest represents a request to add an item to cart.
+// With a product catalog configured, name, category, tax class, price and
+// currency come from the catalog and the client's values are ignored.
+// Otherwise ProductName and a positive ProductPrice are required; the price
+// accepts either a JSON number or a decimal string, and is validated by the
+// logic layer since Money is not a scalar for the validator.
+// Currency defaults to DefaultCurrency when omitted.
 type AddToCartRequest struct {
-	ProductID    string  `json:"product_id" binding:"required"`
-	ProductName  string  `json:"product_name" binding:"required"`
-	ProductPrice float64 `json:"product_price" binding:"required,min=0"`
-	Quantity     int     `json:"quantity" binding:"required,min=1"`
+	ProductID       string `json:"product_id" binding:"required"`
+	ProductName     string `json:"product_name" binding:"max=255"`
+	ProductCategory string `json:"product_category" binding:"max=100"` // Used for category-targeted promotions
+	TaxClass        string `json:"tax_class" binding:"max=32"`         // Defaults to "standard"
+	ProductPrice    Money  `json:"product_price"`
+	Currency        string `json:"currency" binding:"omitempty,iso4217"`
+	Quantity        int    `json:"quantity" binding:"required,min=1"`
+}
+
+// ApplyCouponRequest represents a request to attach a coupon code to the cart
+type ApplyCouponRequest struct {
+	Code string `json:"code" binding:"required,max=64"`
+}
+
+// UnmarshalJSON decodes the currency first so product_price is parsed
+// with the correct number of minor-unit decimals.
+func (r *AddToCartRequest) UnmarshalJSON(data []byte) error {
+	var head struct {
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return err
+	}
+
+	type plain AddToCartRequest
+	req := plain{ProductPrice: Money{Currency: strings.ToUpper(head.Currency)}}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return err
+	}
+	req.Currency = strings.ToUpper(req.Currency)
+
+	*r = AddToCartRequest(req)
+	return nil
+}
+
+// UpdateCartRequest represents a request to change the cart-level fields
+type UpdateCartRequest struct {
+	Name  *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Notes *string `json:"notes" binding:"omitempty,max=500"`
+}
+
+// CreateCartRequest represents a request to create a named cart or wishlist.
+// Kind defaults to KindCart.
+type CreateCartRequest struct {
+	Name string   `json:"name" binding:"required,max=100"`
+	Kind CartKind `json:"kind" binding:"omitempty,oneof=cart wishlist"`
+}
+
+// MergeCartRequest represents a request to merge the guest cart into the user's cart.
+// Policy defaults to the configured CART_MERGE_POLICY.
+type MergeCartRequest struct {
+	Policy MergePolicy `json:"policy" binding:"omitempty,oneof=sum max keep_user"`
 }
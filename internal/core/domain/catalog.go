@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCatalogUnavailable indicates the product catalog could not be reached
+var ErrCatalogUnavailable = errors.New("product catalog unavailable")
+
+// Product is the catalog's authoritative view of a product
+type Product struct {
+	ID        string
+	Name      string
+	Category  string
+	TaxClass  string // Empty when the catalog does not assign one
+	Price     Money
+	Available bool // Whether the product can currently be ordered
+}
+
+// ProductCatalog resolves products against the product service
+type ProductCatalog interface {
+	// GetProduct returns ErrNotFound for an unknown product
+	GetProduct(ctx context.Context, productID string) (*Product, error)
+	// GetProducts returns the known products among productIDs keyed by ID;
+	// unknown IDs are left out
+	GetProducts(ctx context.Context, productIDs []string) (map[string]Product, error)
+}
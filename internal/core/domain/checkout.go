@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SnapshotStatus is the state of a checkout snapshot
+type SnapshotStatus string
+
+const (
+	SnapshotPending   SnapshotStatus = "pending"   // Cart locked, waiting for the order service
+	SnapshotConfirmed SnapshotStatus = "confirmed" // Order placed, cart emptied and unlocked
+	SnapshotCancelled SnapshotStatus = "cancelled" // Order abandoned, cart unlocked as it was
+	SnapshotExpired   SnapshotStatus = "expired"   // Still pending after its lock lapsed
+)
+
+// CartSnapshot is a priced cart frozen at checkout. It never changes after it
+// is taken; the order service places the order from it and then confirms or
+// cancels it, which releases the cart lock.
+type CartSnapshot struct {
+	ID          string            `json:"snapshot_id"`
+	CartID      string            `json:"cart_id"`
+	UserID      string            `json:"user_id"`
+	CartVersion int64             `json:"cart_version"` // Cart version the snapshot was taken from
+	Status      SnapshotStatus    `json:"status"`
+	OrderID     string            `json:"order_id,omitempty"`
+	Currency    string            `json:"currency"`
+	Items       []CartItem        `json:"items"`
+	Subtotal    Money             `json:"subtotal"`
+	Discount    Money             `json:"discount"`
+	Discounts   []AppliedDiscount `json:"discounts"`
+	Shipping    Money             `json:"shipping"`
+	Tax         Money             `json:"tax"`
+	TaxIncluded bool              `json:"tax_inclusive"`
+	Total       Money             `json:"total"`
+	ItemCount   int               `json:"item_count"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ExpiresAt   time.Time         `json:"expires_at"` // The cart lock lapses then unless resolved
+	ResolvedAt  *time.Time        `json:"resolved_at,omitempty"`
+}
+
+// NewCartSnapshot freezes a priced cart into a pending snapshot expiring at expiresAt
+func NewCartSnapshot(cart *Cart, expiresAt time.Time) *CartSnapshot {
+	return &CartSnapshot{
+		CartID:      cart.ID,
+		UserID:      cart.UserID,
+		CartVersion: cart.Version,
+		Status:      SnapshotPending,
+		Currency:    cart.Currency,
+		Items:       cart.Items,
+		Subtotal:    cart.Subtotal,
+		Discount:    cart.Discount,
+		Discounts:   cart.Discounts,
+		Shipping:    cart.Shipping,
+		Tax:         cart.Tax,
+		TaxIncluded: cart.TaxIncluded,
+		Total:       cart.Total,
+		ItemCount:   cart.ItemCount,
+		ExpiresAt:   expiresAt,
+	}
+}
+
+// CheckoutRequest holds the optional shipping and tax location for checkout
+type CheckoutRequest struct {
+	Region  string `json:"region"`                                       // Shipping region used to pick the shipping rule
+	Country string `json:"country" binding:"omitempty,iso3166_1_alpha2"` // Tax jurisdiction country
+	State   string `json:"state" binding:"omitempty,max=3"`              // Tax jurisdiction subdivision, e.g. "CA"
+}
+
+// ConfirmCheckoutRequest is sent by the order service once the order is placed
+type ConfirmCheckoutRequest struct {
+	OrderID string `json:"order_id" binding:"required,max=64"`
+}
+
+// CheckoutRepository stores checkout snapshots and the cart locks they hold
+type CheckoutRepository interface {
+	// Lock stores snapshot and locks the cart it was taken from, selected in ctx
+	// like other cart operations, until snapshot.ExpiresAt; it sets the snapshot's
+	// ID and CreatedAt. Returns ErrVersionMismatch if the cart is no longer at
+	// snapshot.CartVersion and ErrCartLocked if it is already locked.
+	Lock(ctx context.Context, userID string, snapshot *CartSnapshot) error
+	// Get returns a snapshot; ErrNotFound if it does not exist
+	Get(ctx context.Context, id string) (*CartSnapshot, error)
+	// Confirm records orderID on a pending, unexpired snapshot, redeems its
+	// coupons, empties its cart and unlocks it. Confirming again with the same
+	// order ID is a no-op. Returns ErrNotFound for an unknown snapshot and
+	// ErrConflict otherwise.
+	Confirm(ctx context.Context, id, orderID string) error
+	// Cancel unlocks the cart of a pending snapshot. Cancelling again is a no-op.
+	// Returns ErrNotFound for an unknown snapshot and ErrConflict once it is confirmed.
+	Cancel(ctx context.Context, id string) error
+}
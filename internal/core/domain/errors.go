@@ -1,10 +1,31 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Common domain errors
 var (
 	ErrNotFound     = errors.New("resource not found")
 	ErrInvalidInput = errors.New("invalid input")
 	ErrConflict     = errors.New("resource conflict")
+
+	// ErrCurrencyMismatch indicates arithmetic between amounts of different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+
+	// ErrCouponExhausted indicates every use of a coupon is taken by other carts or placed orders
+	ErrCouponExhausted = errors.New("coupon usage limit reached")
+
+	// ErrCartNotFound indicates the selected cart does not exist or belongs to another user
+	ErrCartNotFound = errors.New("cart not found")
+
+	// ErrVersionMismatch indicates the cart changed since the version named by the caller
+	ErrVersionMismatch = errors.New("cart version mismatch")
+
+	// ErrCartFull indicates the cart already holds the maximum number of lines
+	ErrCartFull = errors.New("cart line limit reached")
+
+	// ErrCartLocked indicates the cart is locked by a pending checkout
+	ErrCartLocked = fmt.Errorf("cart locked for checkout: %w", ErrConflict)
 )
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// EventType names a cart domain event
+type EventType string
+
+const (
+	EventCartItemAdded           EventType = "cart.item_added"
+	EventCartItemQuantityChanged EventType = "cart.item_quantity_changed"
+	EventCartItemRemoved         EventType = "cart.item_removed"
+	EventCartCleared             EventType = "cart.cleared"
+	EventCartAbandoned           EventType = "cart.abandoned"
+	EventCartCheckedOut          EventType = "cart.checked_out"
+)
+
+// EventTypes lists every cart event type
+var EventTypes = []EventType{
+	EventCartItemAdded,
+	EventCartItemQuantityChanged,
+	EventCartItemRemoved,
+	EventCartCleared,
+	EventCartAbandoned,
+	EventCartCheckedOut,
+}
+
+// EventPayload is the typed body of a cart event
+type EventPayload interface {
+	EventType() EventType
+}
+
+// CartItemAdded records units of a product added to a cart, by AddItem, a move
+// from the saved list or a merge
+type CartItemAdded struct {
+	ItemID       string `json:"item_id"`
+	ProductID    string `json:"product_id"`
+	Quantity     int    `json:"quantity"`      // Units added
+	LineQuantity int    `json:"line_quantity"` // Quantity of the cart line afterwards
+	ProductPrice Money  `json:"product_price"`
+}
+
+// CartItemQuantityChanged records a cart line set to a new quantity
+type CartItemQuantityChanged struct {
+	ItemID      string `json:"item_id"`
+	ProductID   string `json:"product_id"`
+	OldQuantity int    `json:"old_quantity"`
+	NewQuantity int    `json:"new_quantity"`
+}
+
+// CartItemRemoved records a cart line removed from a cart, by RemoveItem or a
+// move to the saved list
+type CartItemRemoved struct {
+	ItemID    string `json:"item_id"`
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// CartCleared records every line of a cart being removed at once
+type CartCleared struct {
+	ItemCount int `json:"item_count"` // Lines removed
+}
+
+// CartMarkedAbandoned records a cart marked abandoned by the sweeper after an
+// idle period, e.g. for marketing reminders
+type CartMarkedAbandoned struct {
+	ItemCount      int       `json:"item_count"` // Total quantity
+	Subtotal       Money     `json:"subtotal"`   // At the prices stored when items were added
+	LastActivityAt time.Time `json:"last_activity_at"`
+	ExpiresAt      time.Time `json:"expires_at"` // When the cart is deleted unless it is used again
+}
+
+// CartCheckoutConfirmed records a confirmed order placed from a checkout snapshot; the
+// cart's lines were removed
+type CartCheckoutConfirmed struct {
+	SnapshotID string `json:"snapshot_id"`
+	OrderID    string `json:"order_id"`
+	ItemCount  int    `json:"item_count"` // Lines removed
+}
+
+func (CartItemAdded) EventType() EventType           { return EventCartItemAdded }
+func (CartItemQuantityChanged) EventType() EventType { return EventCartItemQuantityChanged }
+func (CartItemRemoved) EventType() EventType         { return EventCartItemRemoved }
+func (CartCleared) EventType() EventType             { return EventCartCleared }
+func (CartMarkedAbandoned) EventType() EventType     { return EventCartAbandoned }
+func (CartCheckoutConfirmed) EventType() EventType   { return EventCartCheckedOut }
+
+// Event is a cart event as stored in the outbox and handed to publishers. ID is
+// unique and stable across redeliveries, so consumers can drop duplicates.
+type Event struct {
+	ID         string          `json:"id"`
+	Type       EventType       `json:"type"`
+	CartID     string          `json:"cart_id"`
+	UserID     string          `json:"user_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"` // The JSON encoded EventPayload
+	Attempts   int             `json:"-"`    // Delivery attempts so far, including the current one
+}
+
+// EventPublisher delivers cart events to other services. Delivery is at least
+// once: an event is published again if the previous attempt failed or its
+// outcome was lost.
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// OutboxRepository holds events written by cart mutations until they are published.
+// Claims are leases, so several relays can share the outbox.
+type OutboxRepository interface {
+	// Claim leases up to limit due, unpublished events for lease, oldest first,
+	// and counts the attempt. Events whose lease lapses are claimed again.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Event, error)
+	// MarkPublished records the events as delivered
+	MarkPublished(ctx context.Context, ids []string) error
+	// Retry schedules the events for another attempt at retryAt, recording cause
+	Retry(ctx context.Context, ids []string, retryAt time.Time, cause string) error
+	// DeletePublished removes events published before the given time and returns how many were deleted
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
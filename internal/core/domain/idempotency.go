@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyRecord is the stored outcome of a request sent with an Idempotency-Key
+type IdempotencyRecord struct {
+	OwnerID     string // Cart owner that sent the key; keys are scoped per owner
+	Key         string
+	Fingerprint string            // SHA-256 of method, path and body
+	StatusCode  int               // 0 while the original request is still in progress
+	Headers     map[string]string // Response headers replayed with the body
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the original request has finished and can be replayed
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// IdempotencyRepository stores Idempotency-Key records
+type IdempotencyRepository interface {
+	// Reserve claims rec.Key for a new request and returns (nil, true). If an
+	// unexpired record already holds the key it is returned with false instead.
+	Reserve(ctx context.Context, rec *IdempotencyRecord) (*IdempotencyRecord, bool, error)
+	// Complete stores the response of a reserved request
+	Complete(ctx context.Context, rec *IdempotencyRecord) error
+	// Release drops a reservation so the request can be retried with the same key
+	Release(ctx context.Context, ownerID, key string) error
+	// DeleteExpired removes expired records and returns how many were deleted
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInventoryUnavailable indicates the inventory service could not be reached
+var ErrInventoryUnavailable = errors.New("inventory unavailable")
+
+// StockStatus tells whether the stock covers a cart item's quantity
+type StockStatus string
+
+const (
+	StockInStock    StockStatus = "in_stock"     // The full quantity is available
+	StockLimited    StockStatus = "limited"      // Some, but fewer than the quantity in the cart
+	StockOutOfStock StockStatus = "out_of_stock" // None available
+)
+
+// StockStatusFor returns the status of quantity units given the available stock
+func StockStatusFor(quantity, available int) StockStatus {
+	switch {
+	case available <= 0:
+		return StockOutOfStock
+	case available < quantity:
+		return StockLimited
+	}
+	return StockInStock
+}
+
+// InventoryClient reads stock levels from the inventory service
+type InventoryClient interface {
+	// Stock returns the quantity on hand of each of productIDs. Products the
+	// inventory does not track are left out and count as out of stock.
+	Stock(ctx context.Context, productIDs []string) (map[string]int, error)
+}
+
+// ReservationRepository stores soft reservations: the quantities of cart items
+// held for a while after the cart last changed. They are not known to the
+// inventory service; the cart service subtracts them from the stock it reports.
+type ReservationRepository interface {
+	// Refresh reserves every item of the selected or active cart at its current
+	// quantity until expiresAt, replacing the cart's earlier reservations.
+	// Wishlists hold no reservations.
+	Refresh(ctx context.Context, userID string, expiresAt time.Time) error
+	// HeldByOthers returns the unexpired reserved quantity of each of productIDs
+	// held by the carts of other users
+	HeldByOthers(ctx context.Context, userID string, productIDs []string) (map[string]int, error)
+	// DeleteExpired removes expired reservations and returns how many were deleted
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "testing"
+
+func TestStockStatusFor(t *testing.T) {
+	tests := []struct {
+		quantity, available int
+		want                StockStatus
+	}{
+		{2, 5, StockInStock},
+		{2, 2, StockInStock},
+		{3, 2, StockLimited},
+		{1, 0, StockOutOfStock},
+		{1, -1, StockOutOfStock},
+	}
+	for _, tt := range tests {
+		if got := StockStatusFor(tt.quantity, tt.available); got != tt.want {
+			t.Errorf("StockStatusFor(%d, %d) = %q, want %q", tt.quantity, tt.available, got, tt.want)
+		}
+	}
+}
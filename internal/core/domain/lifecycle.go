@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AbandonedCart describes a cart that was marked abandoned
+type AbandonedCart struct {
+	CartID         string
+	UserID         string // auth.users.id, or guest:<id> for guest carts
+	Currency       string
+	ItemCount      int   // Total quantity
+	Subtotal       Money // At the prices stored when items were added
+	LastActivityAt time.Time
+	AbandonedAt    time.Time
+	ExpiresAt      time.Time // When the cart is deleted unless it is used again
+}
+
+// CartLifecycleRepository runs the cart lifecycle transitions that happen
+// without a request: abandonment of idle carts and deletion of expired ones.
+// Both operations lock the carts they process with SKIP LOCKED, so concurrent
+// callers on several replicas split the work instead of repeating it.
+type CartLifecycleRepository interface {
+	// MarkAbandoned marks up to limit active, non-empty carts (not wishlists)
+	// whose last change is older than idleBefore as abandoned, sets them to
+	// expire at expiresAt, writes a cart.abandoned event for each to the outbox
+	// in the same transaction, and returns them
+	MarkAbandoned(ctx context.Context, idleBefore, expiresAt time.Time, limit int) ([]AbandonedCart, error)
+	// DeleteExpired deletes up to limit carts whose expires_at has passed,
+	// with their items, coupons and reservations, writes a cart.cleared event
+	// for each deleted cart that had items, and returns how many were deleted
+	DeleteExpired(ctx context.Context, limit int) (int64, error)
+}
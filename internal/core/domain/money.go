@@ -0,0 +1,360 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultCurrency is the ISO 4217 currency used when none is specified
+const DefaultCurrency = "USD"
+
+// ErrInvalidMoney indicates a monetary amount could not be parsed or represented exactly
+var ErrInvalidMoney = errors.New("invalid monetary amount")
+
+// currencyExponents lists ISO 4217 currencies whose minor unit is not 1/100.
+// Every currency not listed here uses two decimal places.
+var currencyExponents = map[string]int{
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+// CurrencyExponent returns the number of decimal places of the currency's minor unit
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+// RoundingMode controls how amounts that fall between two minor units are rounded
+type RoundingMode int
+
+const (
+	RoundHalfEven RoundingMode = iota // Banker's rounding: ties go to the even neighbour
+	RoundHalfUp                       // Ties go away from zero
+	RoundDown                         // Truncate toward zero
+	RoundUp                           // Away from zero
+	RoundFloor                        // Toward negative infinity
+	RoundCeiling                      // Toward positive infinity
+)
+
+// Money is an exact monetary amount stored as integer minor units (e.g. cents)
+// of an ISO 4217 currency. Use the arithmetic helpers instead of float64 math.
+type Money struct {
+	Amount   int64  // Amount in minor units of Currency
+	Currency string // ISO 4217 currency code
+}
+
+// NewMoney creates a Money from minor units
+func NewMoney(minor int64, currency string) Money {
+	return Money{Amount: minor, Currency: strings.ToUpper(currency)}
+}
+
+// Zero returns a zero amount in the given currency
+func Zero(currency string) Money {
+	return NewMoney(0, currency)
+}
+
+// ParseMoney parses a decimal string such as "29.99" into Money.
+// It fails if the value has more decimal places than the currency supports.
+func ParseMoney(s, currency string) (Money, error) {
+	r, err := parseDecimal(s)
+	if err != nil {
+		return Money{}, err
+	}
+	scaled := new(big.Rat).Mul(r, scaleFactor(currency))
+	if !scaled.IsInt() {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places for %s",
+			ErrInvalidMoney, s, CurrencyExponent(currency), currency)
+	}
+	return MoneyFromRat(r, currency, RoundHalfEven)
+}
+
+// MoneyFromRat converts an exact rational amount (in major units) to Money using the given rounding mode
+func MoneyFromRat(r *big.Rat, currency string, mode RoundingMode) (Money, error) {
+	scaled := new(big.Rat).Mul(r, scaleFactor(currency))
+	minor, err := roundRat(scaled, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(minor, currency), nil
+}
+
+// MoneyFromFloat converts a configuration value such as 5.00 to Money, rounding half-even.
+// Do not use it for values that take part in arithmetic before conversion.
+func MoneyFromFloat(f float64, currency string) Money {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	if !ok {
+		return Zero(currency)
+	}
+	m, err := MoneyFromRat(r, currency, RoundHalfEven)
+	if err != nil {
+		return Zero(currency)
+	}
+	return m
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool { return m.Amount == 0 }
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+// IsPositive reports whether the amount is above zero
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+
+// Add returns m + o. Both amounts must share a currency.
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return NewMoney(m.Amount+o.Amount, m.Currency), nil
+}
+
+// Sub returns m - o. Both amounts must share a currency.
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return NewMoney(m.Amount-o.Amount, m.Currency), nil
+}
+
+// Mul returns m multiplied by an integer quantity (exact)
+func (m Money) Mul(n int64) Money {
+	return NewMoney(m.Amount*n, m.Currency)
+}
+
+// MulRat returns m multiplied by a rational factor (e.g. a tax or exchange rate),
+// rounded to the currency's minor unit with the given mode
+func (m Money) MulRat(factor *big.Rat, mode RoundingMode) (Money, error) {
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), factor)
+	minor, err := roundRat(product, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(minor, m.Currency), nil
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return NewMoney(-m.Amount, m.Currency)
+}
+
+// Cmp compares m with o and returns -1, 0 or +1. Both amounts must share a currency.
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Rat returns the amount in major units as an exact rational
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).Quo(new(big.Rat).SetInt64(m.Amount), scaleFactor(m.currency()))
+}
+
+// Float64 returns an approximate value for metrics and tracing only
+func (m Money) Float64() float64 {
+	f, _ := m.Rat().Float64()
+	return f
+}
+
+// Decimal formats the amount in major units, e.g. "29.99"
+func (m Money) Decimal() string {
+	exp := CurrencyExponent(m.currency())
+	sign := ""
+	abs := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		abs = uint64(-(m.Amount + 1)) + 1
+	}
+	digits := strconv.FormatUint(abs, 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// String implements fmt.Stringer, e.g. "29.99 USD"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.currency()
+}
+
+// MoneyJSONFormat selects how Money amounts are encoded in JSON responses
+type MoneyJSONFormat int32
+
+const (
+	// MoneyJSONNumber encodes amounts as JSON numbers (29.99) for existing frontends
+	MoneyJSONNumber MoneyJSONFormat = iota
+	// MoneyJSONString encodes amounts as JSON strings ("29.99") to avoid float parsing on clients
+	MoneyJSONString
+)
+
+var moneyJSONFormat atomic.Int32
+
+// SetMoneyJSONFormat sets the process-wide JSON encoding for Money. Call once at startup.
+func SetMoneyJSONFormat(f MoneyJSONFormat) {
+	moneyJSONFormat.Store(int32(f))
+}
+
+// ParseMoneyJSONFormat maps a MONEY_JSON_FORMAT value to a MoneyJSONFormat
+func ParseMoneyJSONFormat(s string) (MoneyJSONFormat, error) {
+	switch strings.ToLower(s) {
+	case "number", "":
+		return MoneyJSONNumber, nil
+	case "string":
+		return MoneyJSONString, nil
+	default:
+		return MoneyJSONNumber, fmt.Errorf("unknown money JSON format %q", s)
+	}
+}
+
+// MarshalJSON implements json.Marshaler. The decimal text is emitted verbatim,
+// so number mode never goes through float64 either.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if MoneyJSONFormat(moneyJSONFormat.Load()) == MoneyJSONString {
+		return []byte(`"` + m.Decimal() + `"`), nil
+	}
+	return []byte(m.Decimal()), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler and accepts both 29.99 and "29.99".
+// The amount is interpreted in m.Currency, or DefaultCurrency when unset.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	parsed, err := ParseMoney(text, m.currency())
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner for NUMERIC columns. pgx hands NUMERIC values
+// to sql.Scanner implementations as their decimal text representation.
+func (m *Money) Scan(src any) error {
+	var text string
+	switch v := src.(type) {
+	case nil:
+		*m = Zero(m.currency())
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	case int64:
+		text = strconv.FormatInt(v, 10)
+	default:
+		return fmt.Errorf("%w: cannot scan %T into Money", ErrInvalidMoney, src)
+	}
+
+	r, err := parseDecimal(text)
+	if err != nil {
+		return err
+	}
+	parsed, err := MoneyFromRat(r, m.currency(), RoundHalfEven)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value implements driver.Valuer so Money can be passed directly as a NUMERIC parameter
+func (m Money) Value() (driver.Value, error) {
+	return m.Decimal(), nil
+}
+
+func (m Money) currency() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+	return m.Currency
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if m.currency() != o.currency() {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency(), o.currency())
+	}
+	return nil
+}
+
+// parseDecimal parses a plain decimal string, rejecting fractions and exponents
+func parseDecimal(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/eE") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	return r, nil
+}
+
+// scaleFactor returns 10^exponent for the currency
+func scaleFactor(currency string) *big.Rat {
+	exp := CurrencyExponent(currency)
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+}
+
+// roundRat rounds r to an integer using mode
+func roundRat(r *big.Rat, mode RoundingMode) (int64, error) {
+	q, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() != 0 {
+		negative := r.Sign() < 0
+		twiceRem := new(big.Int).Lsh(new(big.Int).Abs(rem), 1)
+		half := twiceRem.Cmp(r.Denom())
+
+		var awayFromZero bool
+		switch mode {
+		case RoundHalfEven:
+			awayFromZero = half > 0 || (half == 0 && q.Bit(0) == 1)
+		case RoundHalfUp:
+			awayFromZero = half >= 0
+		case RoundDown:
+			awayFromZero = false
+		case RoundUp:
+			awayFromZero = true
+		case RoundFloor:
+			awayFromZero = negative
+		case RoundCeiling:
+			awayFromZero = !negative
+		}
+
+		if awayFromZero {
+			if negative {
+				q.Sub(q, big.NewInt(1))
+			} else {
+				q.Add(q, big.NewInt(1))
+			}
+		}
+	}
+
+	if !q.IsInt64() {
+		return 0, fmt.Errorf("%w: amount out of range", ErrInvalidMoney)
+	}
+	return q.Int64(), nil
+}
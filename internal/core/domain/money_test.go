@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     int64
+		wantErr  bool
+	}{
+		{"29.99", "USD", 2999, false},
+		{"0.1", "USD", 10, false},
+		{"-5", "USD", -500, false},
+		{"1500", "JPY", 1500, false},
+		{"1500.00", "JPY", 1500, false},
+		{"1.234", "KWD", 1234, false},
+		{"29.999", "USD", 0, true},
+		{"12.5", "JPY", 0, true},
+		{"1e3", "USD", 0, true},
+		{"abc", "USD", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in+"_"+tt.currency, func(t *testing.T) {
+			got, err := ParseMoney(tt.in, tt.currency)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMoney() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Amount != tt.want {
+				t.Errorf("ParseMoney() = %d, want %d", got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{NewMoney(2999, "USD"), "29.99"},
+		{NewMoney(5, "USD"), "0.05"},
+		{NewMoney(-5, "USD"), "-0.05"},
+		{NewMoney(1500, "JPY"), "1500"},
+		{NewMoney(1234, "KWD"), "1.234"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Decimal(); got != tt.want {
+			t.Errorf("Decimal() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMulRatRounding(t *testing.T) {
+	// 0.25 * 0.5 = 0.125 -> tie between 0.12 and 0.13
+	m := NewMoney(25, "USD")
+	half := big.NewRat(1, 2)
+
+	tests := []struct {
+		mode RoundingMode
+		want int64
+	}{
+		{RoundHalfEven, 12},
+		{RoundHalfUp, 13},
+		{RoundDown, 12},
+		{RoundUp, 13},
+		{RoundFloor, 12},
+		{RoundCeiling, 13},
+	}
+	for _, tt := range tests {
+		got, err := m.MulRat(half, tt.mode)
+		if err != nil {
+			t.Fatalf("MulRat() error = %v", err)
+		}
+		if got.Amount != tt.want {
+			t.Errorf("MulRat(mode=%d) = %d, want %d", tt.mode, got.Amount, tt.want)
+		}
+	}
+
+	neg, _ := m.Neg().MulRat(half, RoundFloor)
+	if neg.Amount != -13 {
+		t.Errorf("MulRat(-0.25 * 0.5, floor) = %d, want -13", neg.Amount)
+	}
+}
+
+func TestMoneyAddCurrencyMismatch(t *testing.T) {
+	_, err := NewMoney(100, "USD").Add(NewMoney(100, "EUR"))
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("Add() error = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestMoneyCmp(t *testing.T) {
+	if cmp, err := NewMoney(100, "USD").Cmp(NewMoney(250, "USD")); err != nil || cmp != -1 {
+		t.Errorf("Cmp(1.00 USD, 2.50 USD) = %d, %v, want -1", cmp, err)
+	}
+	if _, err := NewMoney(100, "USD").Cmp(NewMoney(100, "JPY")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Cmp(USD, JPY) error = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	t.Cleanup(func() { SetMoneyJSONFormat(MoneyJSONNumber) })
+
+	m := NewMoney(2999, "USD")
+
+	SetMoneyJSONFormat(MoneyJSONNumber)
+	if b, _ := json.Marshal(m); string(b) != "29.99" {
+		t.Errorf("number mode = %s, want 29.99", b)
+	}
+
+	SetMoneyJSONFormat(MoneyJSONString)
+	if b, _ := json.Marshal(m); string(b) != `"29.99"` {
+		t.Errorf("string mode = %s, want \"29.99\"", b)
+	}
+
+	for _, in := range []string{`29.99`, `"29.99"`} {
+		var got Money
+		if err := json.Unmarshal([]byte(in), &got); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", in, err)
+		}
+		if got.Amount != 2999 || got.Currency != DefaultCurrency {
+			t.Errorf("Unmarshal(%s) = %+v", in, got)
+		}
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	var m Money
+	if err := m.Scan("79.99"); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if m.Amount != 7999 || m.Currency != DefaultCurrency {
+		t.Errorf("Scan() = %+v", m)
+	}
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PromotionType identifies the discount rule of a promotion
+type PromotionType string
+
+const (
+	PromotionPercentOff PromotionType = "percent_off" // PercentOff percent of the targeted amount
+	PromotionFixedOff   PromotionType = "fixed_off"   // AmountOff off the targeted amount
+	PromotionBuyXGetY   PromotionType = "buy_x_get_y" // Every BuyQuantity+GetQuantity units, GetQuantity are free
+)
+
+// Discount scopes reported in AppliedDiscount
+const (
+	DiscountScopeItem  = "item"
+	DiscountScopeOrder = "order"
+)
+
+// Promotion is a coupon-activated discount rule.
+// A promotion without ProductIDs and Categories targets the whole cart;
+// otherwise it only applies to items matching either list.
+type Promotion struct {
+	ID          string
+	Code        string
+	Description string
+	Type        PromotionType
+	PercentOff  int   // 1-100, percent_off only
+	AmountOff   Money // fixed_off only
+	BuyQuantity int   // buy_x_get_y only
+	GetQuantity int   // buy_x_get_y only
+	ProductIDs  []string
+	Categories  []string
+	MinSubtotal Money // Zero means no minimum
+	UsageLimit  int   // Total uses allowed; zero means unlimited
+	UsageCount  int   // Uses redeemed by placed orders, not counting carts holding the coupon
+	StartsAt    *time.Time
+	EndsAt      *time.Time
+	Active      bool
+}
+
+// AppliedDiscount is one line of the cart discount breakdown.
+// Item-scoped discounts reference the cart item they reduce.
+type AppliedDiscount struct {
+	Code        string `json:"code"`
+	Description string `json:"description,omitempty"`
+	Scope       string `json:"scope"`
+	ItemID      string `json:"item_id,omitempty"`
+	ProductID   string `json:"product_id,omitempty"`
+	Amount      Money  `json:"amount"`
+}
+
+// PromotionRepository defines data access for promotions and the coupons attached to carts
+type PromotionRepository interface {
+	// FindByCode returns ErrNotFound when no promotion has the code
+	FindByCode(ctx context.Context, code string) (*Promotion, error)
+	// ListForCart returns the promotions attached to the user's cart, oldest first
+	ListForCart(ctx context.Context, userID string) ([]Promotion, error)
+	// Attach is idempotent; re-attaching an attached coupon is not an error.
+	// Every cart holding a coupon takes one of its uses until the coupon is
+	// detached; Attach returns ErrCouponExhausted when none is left.
+	Attach(ctx context.Context, userID, promotionID string) error
+	// Detach returns ErrNotFound when the coupon is not attached to the cart
+	Detach(ctx context.Context, userID, code string) error
+}
@@ -2,15 +2,54 @@ package domain
 
 import "context"
 
-// CartRepository defines the interface for cart data access
+// CartRepository defines the interface for cart data access.
+// A user may own several carts. Operations taking a userID act on the cart
+// selected with WithCart, or on the user's active cart if ctx selects none;
+// the active cart is created by its first mutation.
 type CartRepository interface {
 	// Cart operations
 	FindByUserID(ctx context.Context, userID string) (*Cart, error)
 	GetItemCount(ctx context.Context, userID string) (int, error)
 
-	// Item operations
+	// UpdateCart changes the cart-level fields set in update, creating the cart
+	// if the user has none yet
+	UpdateCart(ctx context.Context, userID string, update CartUpdate) error
+
+	// Named carts. SetActiveCart makes cartID the cart served by the /cart routes;
+	// DeleteCart of the active cart leaves the user without one until the next
+	// mutation. Both return ErrCartNotFound if the user does not own cartID.
+	ListCarts(ctx context.Context, userID string) ([]CartSummary, error)
+	CreateCart(ctx context.Context, userID, name string, kind CartKind) (*CartSummary, error)
+	DeleteCart(ctx context.Context, userID, cartID string) error
+	SetActiveCart(ctx context.Context, userID, cartID string) error
+
+	// Item operations. AddItem also removes the product from the saved list.
+	// Every add, including batch adds, moves to the cart and merges, returns
+	// ErrCartFull when a new line would take the cart past its line limit.
 	AddItem(ctx context.Context, userID string, item *CartItem) error
 	UpdateItem(ctx context.Context, userID, itemID string, quantity int) error
 	RemoveItem(ctx context.Context, userID, itemID string) error
 	Clear(ctx context.Context, userID string) error
+
+	// ApplyBatch applies ops to the cart in order in one transaction, each like
+	// the single-item operation of its type, and returns each attempted op's error:
+	// ErrNotFound for a missing line, ErrCurrencyMismatch, or ErrCartFull. With atomic, the first failing
+	// op rolls the whole batch back and the remaining ops are not attempted;
+	// otherwise only the failing op is undone. Errors affecting the whole cart,
+	// such as ErrCartLocked, are returned as the second value.
+	ApplyBatch(ctx context.Context, userID string, ops []BatchOp, atomic bool) ([]error, error)
+
+	// Saved-for-later operations. MoveItem deletes the item from its current list
+	// and upserts it into the other one in a single transaction, summing
+	// quantities like AddItem when the product is already there. Returns the item
+	// as stored in the target list, or ErrNotFound if itemID is not in the source.
+	ListSaved(ctx context.Context, userID string) ([]CartItem, error)
+	MoveItem(ctx context.Context, userID, itemID string, to ItemList) (*CartItem, error)
+	RemoveSaved(ctx context.Context, userID, itemID string) error
+
+	// Merge moves every item of the guest cart into the user's cart in one
+	// transaction, resolving products present in both with policy, and deletes
+	// the guest cart. The guest's saved items join the user's saved list.
+	// Returns ErrCurrencyMismatch if the carts' currencies differ.
+	Merge(ctx context.Context, guestID, userID string, policy MergePolicy) error
 }
@@ -0,0 +1,19 @@
+package domain
+
+import "context"
+
+// selectedCartKey carries the cart a request operates on
+type selectedCartKey struct{}
+
+// WithCart returns a context that makes cart repository operations for a user
+// act on the cart with cartID instead of the user's active cart. Operations fail
+// with ErrCartNotFound if the user does not own that cart.
+func WithCart(ctx context.Context, cartID string) context.Context {
+	return context.WithValue(ctx, selectedCartKey{}, cartID)
+}
+
+// SelectedCart returns the cart ID set by WithCart, or "" for the active cart
+func SelectedCart(ctx context.Context) string {
+	cartID, _ := ctx.Value(selectedCartKey{}).(string)
+	return cartID
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"context"
+	"slices"
+)
+
+// expectedVersionsKey carries the If-Match precondition of a cart mutation
+type expectedVersionsKey struct{}
+
+// WithExpectedVersions returns a context that makes the next cart mutation fail
+// with ErrVersionMismatch unless the cart is currently at one of versions.
+// Repositories check it in the same transaction that performs the mutation.
+func WithExpectedVersions(ctx context.Context, versions ...int64) context.Context {
+	return context.WithValue(ctx, expectedVersionsKey{}, versions)
+}
+
+// VersionMatches reports whether current satisfies the precondition in ctx.
+// Without a precondition every version matches.
+func VersionMatches(ctx context.Context, current int64) bool {
+	versions, ok := ctx.Value(expectedVersionsKey{}).([]int64)
+	if !ok {
+		return true
+	}
+	return slices.Contains(versions, current)
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// WebhookSubscription is an endpoint registered to receive cart events
+type WebhookSubscription struct {
+	ID          string      `json:"id"`
+	URL         string      `json:"url"`
+	EventTypes  []EventType `json:"event_types"` // Empty means every event type
+	Secret      string      `json:"secret,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Active      bool        `json:"active"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// Matches reports whether events of type t are delivered to the subscription
+func (s WebhookSubscription) Matches(t EventType) bool {
+	return len(s.EventTypes) == 0 || slices.Contains(s.EventTypes, t)
+}
+
+// CreateWebhookRequest registers a webhook subscription. Without a secret one is generated.
+type CreateWebhookRequest struct {
+	URL         string      `json:"url" binding:"required,url,max=2048"`
+	EventTypes  []EventType `json:"event_types" binding:"max=16"`
+	Secret      string      `json:"secret" binding:"omitempty,min=16,max=128"`
+	Description string      `json:"description" binding:"max=255"`
+}
+
+// DeliveryStatus is the state of a webhook delivery
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryDead      DeliveryStatus = "dead" // Gave up after the maximum attempts
+)
+
+// WebhookDelivery is one cart event sent, or to be sent, to one subscription
+type WebhookDelivery struct {
+	ID             string         `json:"id"`
+	SubscriptionID string         `json:"subscription_id"`
+	EventID        string         `json:"event_id"`
+	EventType      EventType      `json:"event_type"`
+	Status         DeliveryStatus `json:"status"`
+	Attempts       int            `json:"attempts"`
+	LastStatusCode int            `json:"last_status_code,omitempty"`
+	LastError      string         `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time     `json:"next_attempt_at,omitempty"` // Pending deliveries only
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+
+	// Set on claimed deliveries, for sending
+	Payload json.RawMessage `json:"-"`
+	URL     string          `json:"-"`
+	Secret  string          `json:"-"`
+}
+
+// DeliveryResult is the outcome of one delivery attempt
+type DeliveryResult struct {
+	Status     DeliveryStatus
+	StatusCode int       // HTTP status returned by the endpoint, 0 if there was no response
+	Error      string    // Why the attempt failed
+	RetryAt    time.Time // Next attempt of a delivery left pending
+}
+
+// WebhookRepository stores webhook subscriptions and their deliveries.
+// Claims are leases, like OutboxRepository, so several dispatchers can share the work.
+type WebhookRepository interface {
+	// CreateSubscription stores sub and sets its ID and CreatedAt
+	CreateSubscription(ctx context.Context, sub *WebhookSubscription) error
+	// ListSubscriptions returns every subscription, without secrets
+	ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	// DeleteSubscription deletes a subscription with its deliveries; ErrNotFound if it does not exist
+	DeleteSubscription(ctx context.Context, id string) error
+
+	// Enqueue creates a pending delivery of event for every active subscription
+	// matching its type and returns how many were created. An event already
+	// enqueued for a subscription is skipped.
+	Enqueue(ctx context.Context, event Event) (int, error)
+	// ClaimDeliveries leases up to limit due pending deliveries for lease,
+	// oldest first, and counts the attempt
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error)
+	// RecordResult stores the outcome of a delivery attempt
+	RecordResult(ctx context.Context, id string, result DeliveryResult) error
+	// ListDeliveries returns the latest deliveries of a subscription, newest
+	// first, optionally only those with status; ErrNotFound if the subscription does not exist
+	ListDeliveries(ctx context.Context, subscriptionID string, status DeliveryStatus, limit int) ([]WebhookDelivery, error)
+	// Redeliver makes a dead or delivered delivery pending again with a fresh
+	// attempt count; ErrNotFound if the subscription has no such delivery
+	Redeliver(ctx context.Context, subscriptionID, deliveryID string) error
+}
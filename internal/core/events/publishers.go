@@ -0,0 +1,128 @@
+// Package events provides domain.EventPublisher implementations: a structured
+// log publisher, an HTTP webhook publisher, a fan-out over several publishers and
+// an in-memory publisher for tests. It also signs webhook subscription deliveries.
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/pkg/logger/clog"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// LogPublisher publishes events as structured log records, for log-based pipelines
+type LogPublisher struct{}
+
+// Publish logs the event
+func (LogPublisher) Publish(ctx context.Context, event domain.Event) error {
+	clog.InfoContext(ctx, "Cart event",
+		"event_id", event.ID,
+		"event_type", string(event.Type),
+		"cart_id", event.CartID,
+		"user_id", event.UserID,
+		"occurred_at", event.OccurredAt,
+		"data", string(event.Data),
+	)
+	return nil
+}
+
+// WebhookPublisher POSTs each event as JSON to a fixed URL. Any 2xx response
+// counts as delivered. The event ID and type are also sent as X-Event-ID and
+// X-Event-Type headers.
+type WebhookPublisher struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewWebhookPublisher creates a webhook publisher; timeout bounds each delivery
+// and defaults to 5s when zero
+func NewWebhookPublisher(url string, timeout time.Duration) *WebhookPublisher {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &WebhookPublisher{url: url, httpClient: &http.Client{Timeout: timeout}}
+}
+
+// Publish delivers the event
+func (p *WebhookPublisher) Publish(ctx context.Context, event domain.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", string(event.Type))
+
+	resp, err := p.httpClient.Do(req) // #nosec G704
+	if err != nil {
+		return fmt.Errorf("deliver event %s: %w", event.ID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("deliver event %s: webhook returned %d: %s", event.ID, resp.StatusCode, msg)
+	}
+	return nil
+}
+
+// Fanout publishes each event to every publisher in order. The first error stops
+// the fan-out and is returned, so the outbox retries the event for all of them;
+// publishers must tolerate the duplicates this causes.
+type Fanout []domain.EventPublisher
+
+// Publish delivers the event to every publisher
+func (f Fanout) Publish(ctx context.Context, event domain.Event) error {
+	for _, p := range f {
+		if err := p.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MemoryPublisher collects published events in memory, for tests
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []domain.Event
+	err    error
+}
+
+// Publish records the event, or returns the error set with FailWith
+func (p *MemoryPublisher) Publish(_ context.Context, event domain.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+// FailWith makes Publish return err; nil makes it succeed again
+func (p *MemoryPublisher) FailWith(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// Events returns the events published so far
+func (p *MemoryPublisher) Events() []domain.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]domain.Event(nil), p.events...)
+}
@@ -0,0 +1,48 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+func TestWebhookPublisher(t *testing.T) {
+	status := http.StatusAccepted
+	var received domain.Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Event-ID") != "42" || r.Header.Get("X-Event-Type") != string(domain.EventCartCleared) {
+			t.Errorf("headers = %v, want X-Event-ID and X-Event-Type", r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	publisher := NewWebhookPublisher(srv.URL, time.Second)
+	event := domain.Event{
+		ID:     "42",
+		Type:   domain.EventCartCleared,
+		CartID: "7",
+		UserID: "user1",
+		Data:   json.RawMessage(`{"item_count":3}`),
+	}
+
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if received.CartID != "7" || string(received.Data) != `{"item_count":3}` {
+		t.Errorf("received %+v, want the event", received)
+	}
+
+	status = http.StatusInternalServerError
+	if err := publisher.Publish(context.Background(), event); err == nil {
+		t.Error("Publish() on 500 error = nil, want an error so the event is retried")
+	}
+}
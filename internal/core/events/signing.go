@@ -0,0 +1,77 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+// Headers of signed webhook deliveries
+const (
+	HeaderWebhookID = "X-Webhook-ID"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign returns the X-Webhook-Signature value for body sent at timestamp:
+// "v1=" followed by the hex HMAC-SHA256, keyed with secret, of
+// "<unix seconds>.<body>". Receivers recompute it over the raw body and should
+// reject timestamps too far from their own clock to stop replays.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignedSender POSTs webhook deliveries signed with their subscription's secret
+type SignedSender struct {
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewSignedSender creates a sender; timeout bounds each request and defaults to 5s when zero
+func NewSignedSender(timeout time.Duration) *SignedSender {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &SignedSender{httpClient: &http.Client{Timeout: timeout}, now: time.Now}
+}
+
+// Send delivers d and returns the HTTP status, 0 if there was no response.
+// Any status other than 2xx is an error.
+func (s *SignedSender) Send(ctx context.Context, d domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	timestamp := s.now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookID, d.ID)
+	req.Header.Set("X-Event-ID", d.EventID)
+	req.Header.Set("X-Event-Type", string(d.EventType))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(d.Secret, timestamp, d.Payload))
+
+	resp, err := s.httpClient.Do(req) // #nosec G704 -- URLs are registered by admins
+	if err != nil {
+		return 0, fmt.Errorf("deliver webhook %s: %w", d.ID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("webhook returned %d: %s", resp.StatusCode, msg)
+	}
+	return resp.StatusCode, nil
+}
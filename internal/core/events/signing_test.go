@@ -0,0 +1,73 @@
+package events
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+func TestSign(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"1"}`)
+
+	sig := Sign("secret", ts, body)
+	if sig != Sign("secret", ts, body) {
+		t.Fatal("Sign() is not deterministic")
+	}
+	if len(sig) != len("v1=")+64 || sig[:3] != "v1=" {
+		t.Errorf("Sign() = %q, want v1= and a hex SHA-256 HMAC", sig)
+	}
+	for name, other := range map[string]string{
+		"secret":    Sign("other", ts, body),
+		"timestamp": Sign("secret", ts.Add(time.Second), body),
+		"body":      Sign("secret", ts, []byte(`{"id":"2"}`)),
+	} {
+		if other == sig {
+			t.Errorf("signature does not change with the %s", name)
+		}
+	}
+}
+
+func TestSignedSender(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		unix, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil {
+			t.Errorf("%s = %q, want unix seconds", HeaderTimestamp, r.Header.Get(HeaderTimestamp))
+		}
+		if got, want := r.Header.Get(HeaderSignature), Sign("whsec_test", time.Unix(unix, 0), body); got != want {
+			t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+		}
+		if r.Header.Get(HeaderWebhookID) != "9" || r.Header.Get("X-Event-ID") != "42" {
+			t.Errorf("headers = %v, want the delivery and event IDs", r.Header)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sender := NewSignedSender(time.Second)
+	delivery := domain.WebhookDelivery{
+		ID:        "9",
+		EventID:   "42",
+		EventType: domain.EventCartCleared,
+		Payload:   []byte(`{"id":"42","type":"cart.cleared"}`),
+		URL:       srv.URL,
+		Secret:    "whsec_test",
+	}
+
+	if code, err := sender.Send(context.Background(), delivery); err != nil || code != http.StatusOK {
+		t.Fatalf("Send() = %d, %v, want 200", code, err)
+	}
+
+	status = http.StatusGone
+	if code, err := sender.Send(context.Background(), delivery); err == nil || code != http.StatusGone {
+		t.Errorf("Send() on 410 = %d, %v, want the status and an error", code, err)
+	}
+}
@@ -0,0 +1,81 @@
+// Package inventory provides domain.InventoryClient implementations: a client
+// for the inventory service and an in-memory inventory for tests and local development.
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+const defaultTimeout = 2 * time.Second
+
+// HTTPInventory implements domain.InventoryClient against the inventory service
+// (GET /inventory/v1/internal/stock?product_ids=1,2)
+type HTTPInventory struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPInventory creates an inventory service client; timeout bounds each call
+// and defaults to 2s when zero
+func NewHTTPInventory(baseURL string, timeout time.Duration) *HTTPInventory {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &HTTPInventory{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// stockResponse is the inventory service representation of stock levels
+type stockResponse struct {
+	Stock []struct {
+		ProductID json.RawMessage `json:"product_id"` // JSON number or string
+		Quantity  int             `json:"quantity"`
+	} `json:"stock"`
+}
+
+// Stock fetches the stock of productIDs in one call. Unreachable service,
+// non-2xx responses and malformed bodies wrap domain.ErrInventoryUnavailable.
+func (c *HTTPInventory) Stock(ctx context.Context, productIDs []string) (map[string]int, error) {
+	stock := make(map[string]int, len(productIDs))
+	if len(productIDs) == 0 {
+		return stock, nil
+	}
+
+	query := url.Values{"product_ids": {strings.Join(productIDs, ",")}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.baseURL+"/inventory/v1/internal/stock?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req) // #nosec G704
+	if err != nil {
+		return nil, fmt.Errorf("%w: request inventory service: %w", domain.ErrInventoryUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%w: inventory service returned %d: %s", domain.ErrInventoryUnavailable, resp.StatusCode, body)
+	}
+
+	var body stockResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: decode stock: %w", domain.ErrInventoryUnavailable, err)
+	}
+	for _, s := range body.Stock {
+		stock[strings.Trim(string(s.ProductID), `"`)] = s.Quantity
+	}
+	return stock, nil
+}
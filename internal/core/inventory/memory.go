@@ -0,0 +1,41 @@
+package inventory
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryInventory is an in-memory domain.InventoryClient for tests and local development
+type MemoryInventory struct {
+	mu    sync.RWMutex
+	stock map[string]int
+}
+
+// NewMemoryInventory creates an inventory holding stock, keyed by product ID
+func NewMemoryInventory(stock map[string]int) *MemoryInventory {
+	m := &MemoryInventory{stock: make(map[string]int, len(stock))}
+	for id, quantity := range stock {
+		m.stock[id] = quantity
+	}
+	return m
+}
+
+// Set sets the stock of a product
+func (m *MemoryInventory) Set(productID string, quantity int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stock[productID] = quantity
+}
+
+// Stock returns the stock of the tracked products among productIDs
+func (m *MemoryInventory) Stock(_ context.Context, productIDs []string) (map[string]int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stock := make(map[string]int, len(productIDs))
+	for _, id := range productIDs {
+		if quantity, ok := m.stock[id]; ok {
+			stock[id] = quantity
+		}
+	}
+	return stock, nil
+}
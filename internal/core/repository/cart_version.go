@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// bumpVersion increments the version of the cart selected in ctx, or of the
+// user's active cart, creating the active cart on first use, and returns the
+// cart ID. An abandoned cart, or one whose checkout lock has lapsed, becomes
+// active again. It must be the first statement of every cart mutation
+// transaction: being a write it keeps the transaction on the primary under
+// PgCat, and the header row lock serializes concurrent mutations of the same cart.
+// Returns ErrCartNotFound if the user does not own the selected cart,
+// ErrCartLocked while a checkout holds the cart, and ErrVersionMismatch if the
+// previous version fails the If-Match precondition in ctx. The caller's
+// rollback undoes the bump on any error.
+func bumpVersion(ctx context.Context, tx pgx.Tx, userID string) (int64, error) {
+	var cartID, version int64
+	var status string
+	if selected := domain.SelectedCart(ctx); selected != "" {
+		err := tx.QueryRow(ctx, `
+			UPDATE carts
+			SET version = version + 1, updated_at = NOW(), `+reopenCart+`
+			WHERE id = $1 AND user_id = $2
+			RETURNING id, version, status
+		`, selected, userID).Scan(&cartID, &version, &status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrCartNotFound
+		}
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO carts (user_id, is_active, version, created_at, updated_at)
+			VALUES ($1, TRUE, 1, NOW(), NOW())
+			ON CONFLICT (user_id) WHERE is_active DO UPDATE
+			SET version = carts.version + 1, updated_at = NOW(), `+reopenCart+`
+			RETURNING id, version, status
+		`, userID).Scan(&cartID, &version, &status)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if status == string(domain.CartCheckingOut) {
+		return 0, domain.ErrCartLocked
+	}
+	if !domain.VersionMatches(ctx, version-1) {
+		return 0, domain.ErrVersionMismatch
+	}
+	return cartID, nil
+}
+
+// reopenCart is the SET clause that makes an abandoned cart active again,
+// clearing the expiry the abandonment sweeper gave it, and releases a checkout
+// lock that has lapsed
+const reopenCart = `status = CASE
+			        WHEN carts.status = 'abandoned' THEN 'active'
+			        WHEN carts.status = 'checking_out' AND carts.locked_until <= NOW() THEN 'active'
+			        ELSE carts.status
+			    END,
+			    expires_at = CASE WHEN carts.status = 'abandoned' THEN NULL ELSE carts.expires_at END,
+			    locked_until = CASE WHEN carts.locked_until <= NOW() THEN NULL ELSE carts.locked_until END`
+
+// cartStatus is the status of carts aliased c as mutations see it: a cart whose
+// checkout lock has lapsed is active
+const cartStatus = `CASE WHEN c.status = 'checking_out' AND c.locked_until <= NOW() THEN 'active' ELSE c.status END`
+
+// cartCondition returns the condition on carts (aliased c) that selects the cart
+// for userID in ctx, with its arguments; userID is always $1
+func cartCondition(ctx context.Context, userID string) (string, []any) {
+	if selected := domain.SelectedCart(ctx); selected != "" {
+		return `c.user_id = $1 AND c.id = $2`, []any{userID, selected}
+	}
+	return `c.user_id = $1 AND c.is_active`, []any{userID}
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// ApplyBatch applies ops in one transaction. Each op runs in its own savepoint,
+// so a failing op can be undone without losing the ones before it.
+func (r *PostgresCartRepository) ApplyBatch(ctx context.Context, userID string, ops []domain.BatchOp, atomic bool) ([]error, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	cartID, err := bumpVersion(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, 0, len(ops))
+	for _, op := range ops {
+		err := applyBatchOp(ctx, tx, cartID, userID, op, r.maxLines)
+		errs = append(errs, err)
+		if err == nil {
+			continue
+		}
+		if !isBatchOpError(err) {
+			return nil, err
+		}
+		if atomic {
+			// The deferred rollback undoes the ops already applied
+			return errs, nil
+		}
+	}
+
+	// Leave the version alone if nothing changed
+	if !slices.Contains(errs, nil) {
+		return errs, nil
+	}
+	return errs, tx.Commit(ctx)
+}
+
+// applyBatchOp applies op inside a savepoint, rolling back to it if op fails
+func applyBatchOp(ctx context.Context, tx pgx.Tx, cartID int64, userID string, op domain.BatchOp, maxLines int) error {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = savepoint.Rollback(ctx)
+	}()
+
+	switch op.Type {
+	case domain.BatchAdd:
+		err = addCartItem(ctx, savepoint, cartID, userID, op.Item, maxLines)
+	case domain.BatchUpdate:
+		err = updateCartItem(ctx, savepoint, cartID, userID, op.ItemID, op.Quantity)
+	case domain.BatchRemove:
+		err = removeCartItem(ctx, savepoint, cartID, userID, op.ItemID)
+	default:
+		err = fmt.Errorf("unknown batch operation %q", op.Type)
+	}
+	if err != nil {
+		return err
+	}
+	return savepoint.Commit(ctx)
+}
+
+// checkCartLines returns ErrCartFull if adding productID would take the cart
+// past maxLines lines. Adding to an existing line is always allowed.
+func checkCartLines(ctx context.Context, tx pgx.Tx, cartID int64, productID string, maxLines int) error {
+	if maxLines <= 0 {
+		return nil
+	}
+	var full bool
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) >= $3 AND NOT COALESCE(bool_or(product_id = $2), FALSE)
+		FROM cart_items
+		WHERE cart_id = $1
+	`, cartID, productID, maxLines).Scan(&full)
+	if err != nil {
+		return err
+	}
+	if full {
+		return domain.ErrCartFull
+	}
+	return nil
+}
+
+// isBatchOpError reports whether err is the failure of a single batch op
+// rather than of the whole batch
+func isBatchOpError(err error) bool {
+	return errors.Is(err, domain.ErrNotFound) ||
+		errors.Is(err, domain.ErrCurrencyMismatch) ||
+		errors.Is(err, domain.ErrCartFull)
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresCartLifecycleRepository implements CartLifecycleRepository using PostgreSQL with pgx
+type PostgresCartLifecycleRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresCartLifecycleRepository creates a new PostgreSQL cart lifecycle repository
+func NewPostgresCartLifecycleRepository(pool *pgxpool.Pool) *PostgresCartLifecycleRepository {
+	return &PostgresCartLifecycleRepository{pool: pool}
+}
+
+// MarkAbandoned abandons the longest idle carts first and appends their events
+// in the same transaction. The transaction starts with UPDATE so PgCat routes it
+// to the primary. Carts being changed by a request are locked and skipped;
+// updated_at is left alone and keeps the last activity.
+func (r *PostgresCartLifecycleRepository) MarkAbandoned(ctx context.Context, idleBefore, expiresAt time.Time, limit int) ([]domain.AbandonedCart, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+		UPDATE carts c
+		SET status = 'abandoned', expires_at = $2, version = c.version + 1
+		WHERE c.id IN (
+			SELECT id FROM carts
+			WHERE status = 'active' AND kind = 'cart' AND updated_at < $1
+			  AND EXISTS (SELECT 1 FROM cart_items i WHERE i.cart_id = carts.id)
+			ORDER BY updated_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING c.id::text, c.user_id, c.currency, c.updated_at, NOW(), c.expires_at,
+		          (SELECT COALESCE(SUM(i.quantity), 0) FROM cart_items i WHERE i.cart_id = c.id),
+		          c.currency,
+		          (SELECT COALESCE(SUM(i.product_price * i.quantity), 0) FROM cart_items i WHERE i.cart_id = c.id)
+	`
+
+	rows, err := tx.Query(ctx, query, idleBefore, expiresAt, limit)
+	if err != nil {
+		return nil, err
+	}
+	var carts []domain.AbandonedCart
+	for rows.Next() {
+		var cart domain.AbandonedCart
+		// The second currency column is scanned before the subtotal so Money.Scan uses the right exponent
+		err := rows.Scan(&cart.CartID, &cart.UserID, &cart.Currency, &cart.LastActivityAt, &cart.AbandonedAt,
+			&cart.ExpiresAt, &cart.ItemCount, &cart.Subtotal.Currency, &cart.Subtotal)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		carts = append(carts, cart)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, cart := range carts {
+		cartID, err := strconv.ParseInt(cart.CartID, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		event := domain.CartMarkedAbandoned{
+			ItemCount:      cart.ItemCount,
+			Subtotal:       cart.Subtotal,
+			LastActivityAt: cart.LastActivityAt,
+			ExpiresAt:      cart.ExpiresAt,
+		}
+		if err := appendEvent(ctx, tx, cartID, cart.UserID, event); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return carts, nil
+}
+
+// DeleteExpired deletes expired carts; items, coupons and reservations cascade.
+// A deleted cart that still had items gets a cart.cleared event, like DeleteCart.
+func (r *PostgresCartLifecycleRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Items are counted in the DELETE's snapshot, before they cascade
+	query := `
+		DELETE FROM carts c
+		WHERE c.id IN (
+			SELECT id FROM carts
+			WHERE expires_at <= NOW()
+			ORDER BY expires_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING c.id, c.user_id, (SELECT COUNT(*) FROM cart_items i WHERE i.cart_id = c.id)
+	`
+
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return 0, err
+	}
+	type deletedCart struct {
+		id        int64
+		userID    string
+		itemCount int
+	}
+	var deleted []deletedCart
+	for rows.Next() {
+		var cart deletedCart
+		if err := rows.Scan(&cart.id, &cart.userID, &cart.itemCount); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		deleted = append(deleted, cart)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, cart := range deleted {
+		if cart.itemCount == 0 {
+			continue
+		}
+		if err := appendEvent(ctx, tx, cart.id, cart.userID, domain.CartCleared{ItemCount: cart.itemCount}); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int64(len(deleted)), nil
+}
@@ -2,71 +2,96 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // PostgresCartRepository implements CartRepository using PostgreSQL with pgx
 type PostgresCartRepository struct {
-	pool *pgxpool.Pool
+	pool     *pgxpool.Pool
+	maxLines int
 }
 
-// NewPostgresCartRepository creates a new PostgreSQL cart repository
-func NewPostgresCartRepository(pool *pgxpool.Pool) *PostgresCartRepository {
-	return &PostgresCartRepository{pool: pool}
+// NewPostgresCartRepository creates a new PostgreSQL cart repository. Adds that
+// would take a cart past maxLines lines fail with ErrCartFull; 0 disables the limit.
+func NewPostgresCartRepository(pool *pgxpool.Pool, maxLines int) *PostgresCartRepository {
+	return &PostgresCartRepository{pool: pool, maxLines: maxLines}
 }
 
-// FindByUserID retrieves a cart by user ID
+// FindByUserID retrieves the header and raw items of the user's selected or
+// active cart in one query. A user without an active cart has an empty one at
+// version 0. Totals are computed by the logic layer, not here.
 func (r *PostgresCartRepository) FindByUserID(ctx context.Context, userID string) (*domain.Cart, error) {
+	cond, args := cartCondition(ctx, userID)
+	// Item columns are NULL for a cart without items (LEFT JOIN); COALESCE keeps them
+	// scannable and an empty item ID marks that row.
 	query := `
-		SELECT id, product_id, product_name, product_price, quantity
-		FROM cart_items
-		WHERE user_id = $1
+		SELECT c.id::text, c.name, c.kind, c.is_active, c.version, ` + cartStatus + `, c.notes, c.expires_at,
+		       CASE WHEN c.locked_until > NOW() THEN c.locked_until END, c.currency,
+		       COALESCE(i.id::text, ''), COALESCE(i.product_id::text, ''), COALESCE(i.product_name, ''),
+		       COALESCE(i.product_category, ''), COALESCE(i.tax_class, ''),
+		       COALESCE(i.currency, c.currency), COALESCE(i.product_price, 0), COALESCE(i.quantity, 0)
+		FROM carts c
+		LEFT JOIN cart_items i ON i.cart_id = c.id
+		WHERE ` + cond + `
+		ORDER BY i.id
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var items []domain.CartItem
-	var subtotal float64
+	cart := &domain.Cart{
+		UserID:   userID,
+		Kind:     domain.KindCart,
+		Active:   true,
+		Status:   domain.CartActive,
+		Currency: domain.DefaultCurrency,
+	}
 
 	for rows.Next() {
 		var item domain.CartItem
-		err := rows.Scan(&item.ID, &item.ProductID, &item.ProductName, &item.ProductPrice, &item.Quantity)
+		var kind, status string
+		// currency is scanned before product_price so Money.Scan uses the right exponent
+		err := rows.Scan(&cart.ID, &cart.Name, &kind, &cart.Active, &cart.Version, &status, &cart.Notes,
+			&cart.ExpiresAt, &cart.LockedUntil, &cart.Currency,
+			&item.ID, &item.ProductID, &item.ProductName, &item.ProductCategory, &item.TaxClass,
+			&item.ProductPrice.Currency, &item.ProductPrice, &item.Quantity)
 		if err != nil {
-			continue
+			return nil, err
+		}
+		cart.Kind = domain.CartKind(kind)
+		cart.Status = domain.CartStatus(status)
+		if item.ID != "" {
+			cart.Items = append(cart.Items, item)
 		}
-		item.Subtotal = item.ProductPrice * float64(item.Quantity)
-		subtotal += item.Subtotal
-		items = append(items, item)
 	}
-
-	cart := &domain.Cart{
-		UserID:    userID,
-		Items:     items,
-		Subtotal:  subtotal,
-		Shipping:  5.00,
-		Total:     subtotal + 5.00,
-		ItemCount: len(items),
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
+	if cart.ID == "" && domain.SelectedCart(ctx) != "" {
+		return nil, domain.ErrCartNotFound
+	}
 	return cart, nil
 }
 
 // GetItemCount returns the total number of items in the cart
 func (r *PostgresCartRepository) GetItemCount(ctx context.Context, userID string) (int, error) {
+	cond, args := cartCondition(ctx, userID)
 	query := `
 		SELECT COALESCE(SUM(quantity), 0) as count
-		FROM cart_items
-		WHERE user_id = $1
-	`
+		FROM cart_items i
+		JOIN carts c ON c.id = i.cart_id
+		WHERE ` + cond
 
 	var count int
-	err := r.pool.QueryRow(ctx, query, userID).Scan(&count)
+	err := r.pool.QueryRow(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
@@ -74,6 +99,34 @@ func (r *PostgresCartRepository) GetItemCount(ctx context.Context, userID string
 	return count, nil
 }
 
+// UpdateCart changes the cart-level fields set in update
+func (r *PostgresCartRepository) UpdateCart(ctx context.Context, userID string, update domain.CartUpdate) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	cartID, err := bumpVersion(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE carts
+		SET name = COALESCE($2, name),
+		    notes = COALESCE($3, notes)
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, query, cartID, update.Name, update.Notes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
 // AddItem adds an item to the cart using a single atomic UPSERT.
 // Uses INSERT ... ON CONFLICT to ensure PgCat always routes this to the primary,
 // avoiding SQLSTATE 25006 (read-only transaction) errors from replica routing.
@@ -87,64 +140,363 @@ func (r *PostgresCartRepository) AddItem(ctx context.Context, userID string, ite
 		_ = tx.Rollback(ctx)
 	}()
 
+	cartID, err := bumpVersion(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	if err := addCartItem(ctx, tx, cartID, userID, item, r.maxLines); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// addCartItem adds item to the cart with its event, and takes the product off
+// the user's saved list
+func addCartItem(ctx context.Context, tx pgx.Tx, cartID int64, userID string, item *domain.CartItem, maxLines int) error {
+	added := item.Quantity
+	if err := upsertCartItem(ctx, tx, cartID, item, maxLines); err != nil {
+		return err
+	}
+	if err := appendEvent(ctx, tx, cartID, userID, itemAdded(item, added)); err != nil {
+		return err
+	}
+
+	// A product lives in either the cart or the saved list
+	_, err := tx.Exec(ctx, `DELETE FROM saved_items WHERE user_id = $1 AND product_id = $2`, userID, item.ProductID)
+	return err
+}
+
+// upsertCartItem inserts item into the cart, adding its quantity to the existing
+// line if the product is already there, and sets item.ID and item.Quantity to
+// the stored line. Returns ErrCartFull if a new line would take the cart past
+// maxLines, and ErrCurrencyMismatch if the item's currency differs from the cart's.
+func upsertCartItem(ctx context.Context, tx pgx.Tx, cartID int64, item *domain.CartItem, maxLines int) error {
+	if err := checkCartLines(ctx, tx, cartID, item.ProductID, maxLines); err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO cart_items (user_id, product_id, product_name, product_price, quantity, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
-		ON CONFLICT (user_id, product_id) DO UPDATE
+		INSERT INTO cart_items (cart_id, product_id, product_name, product_category, tax_class, currency, product_price, quantity, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		ON CONFLICT (cart_id, product_id) DO UPDATE
 		SET quantity = cart_items.quantity + EXCLUDED.quantity,
 		    updated_at = NOW()
-		RETURNING id
+		RETURNING id, quantity
 	`
-	err = tx.QueryRow(ctx, query, userID, item.ProductID, item.ProductName, item.ProductPrice, item.Quantity).Scan(&item.ID)
+	err := tx.QueryRow(ctx, query, cartID, item.ProductID, item.ProductName, item.ProductCategory, item.TaxClass,
+		item.ProductPrice.Currency, item.ProductPrice, item.Quantity).Scan(&item.ID, &item.Quantity)
 	if err != nil {
 		return err
 	}
 
-	return tx.Commit(ctx)
+	// A cart holds a single currency. The check runs after the writes so
+	// PgCat keeps the transaction on the primary.
+	var mixed bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM cart_items WHERE cart_id = $1 AND currency <> $2)
+	`, cartID, item.ProductPrice.Currency).Scan(&mixed)
+	if err != nil {
+		return err
+	}
+	if mixed {
+		return domain.ErrCurrencyMismatch
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE carts SET currency = $2 WHERE id = $1`, cartID, item.ProductPrice.Currency)
+	return err
+}
+
+// itemAdded is the event for added units of item, as returned by upsertCartItem
+func itemAdded(item *domain.CartItem, added int) domain.CartItemAdded {
+	return domain.CartItemAdded{
+		ItemID:       item.ID,
+		ProductID:    item.ProductID,
+		Quantity:     added,
+		LineQuantity: item.Quantity,
+		ProductPrice: item.ProductPrice,
+	}
 }
 
 // UpdateItem updates the quantity of a cart item
 func (r *PostgresCartRepository) UpdateItem(ctx context.Context, userID, itemID string, quantity int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	cartID, err := bumpVersion(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	if err := updateCartItem(ctx, tx, cartID, userID, itemID, quantity); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// updateCartItem sets the quantity of line itemID with its event; ErrNotFound
+// if the cart has no such line
+func updateCartItem(ctx context.Context, tx pgx.Tx, cartID int64, userID, itemID string, quantity int) error {
+	// The self-join exposes the quantity before the update
 	query := `
-		UPDATE cart_items
+		UPDATE cart_items i
 		SET quantity = $1, updated_at = NOW()
-		WHERE id = $2 AND user_id = $3
+		FROM cart_items old
+		WHERE old.id = i.id AND i.id = $2 AND i.cart_id = $3
+		RETURNING i.product_id::text, old.quantity
 	`
 
-	result, err := r.pool.Exec(ctx, query, quantity, itemID, userID)
+	event := domain.CartItemQuantityChanged{ItemID: itemID, NewQuantity: quantity}
+	err := tx.QueryRow(ctx, query, quantity, itemID, cartID).Scan(&event.ProductID, &event.OldQuantity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrNotFound
+	}
 	if err != nil {
 		return err
 	}
 
-	if result.RowsAffected() == 0 {
-		return domain.ErrNotFound
+	if event.OldQuantity == event.NewQuantity {
+		return nil
 	}
-
-	return nil
+	return appendEvent(ctx, tx, cartID, userID, event)
 }
 
 // RemoveItem removes a single item from the cart
 func (r *PostgresCartRepository) RemoveItem(ctx context.Context, userID, itemID string) error {
-	query := `
-		DELETE FROM cart_items
-		WHERE id = $1 AND user_id = $2
-	`
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-	result, err := r.pool.Exec(ctx, query, itemID, userID)
+	cartID, err := bumpVersion(ctx, tx, userID)
 	if err != nil {
 		return err
 	}
+	if err := removeCartItem(ctx, tx, cartID, userID, itemID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
 
-	if result.RowsAffected() == 0 {
+// removeCartItem deletes line itemID with its event; ErrNotFound if the cart
+// has no such line
+func removeCartItem(ctx context.Context, tx pgx.Tx, cartID int64, userID, itemID string) error {
+	query := `
+		DELETE FROM cart_items
+		WHERE id = $1 AND cart_id = $2
+		RETURNING product_id::text, quantity
+	`
+
+	event := domain.CartItemRemoved{ItemID: itemID}
+	err := tx.QueryRow(ctx, query, itemID, cartID).Scan(&event.ProductID, &event.Quantity)
+	if errors.Is(err, pgx.ErrNoRows) {
 		return domain.ErrNotFound
 	}
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return appendEvent(ctx, tx, cartID, userID, event)
 }
 
 // Clear removes all items from the cart
 func (r *PostgresCartRepository) Clear(ctx context.Context, userID string) error {
-	query := `DELETE FROM cart_items WHERE user_id = $1`
-	_, err := r.pool.Exec(ctx, query, userID)
-	return err
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	cartID, err := bumpVersion(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM cart_items WHERE cart_id = $1`, cartID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() > 0 {
+		event := domain.CartCleared{ItemCount: int(result.RowsAffected())}
+		if err := appendEvent(ctx, tx, cartID, userID, event); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Merge moves the guest cart into the user's cart in a single transaction.
+// The version bump runs first so the transaction starts with a write (PgCat primary routing).
+func (r *PostgresCartRepository) Merge(ctx context.Context, guestID, userID string, policy domain.MergePolicy) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	cartID, err := bumpVersion(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Carry over the guest's saved items, summing quantities like a move
+	_, err = tx.Exec(ctx, `
+		INSERT INTO saved_items (user_id, product_id, product_name, product_category, tax_class, currency, product_price, quantity, created_at, updated_at)
+		SELECT $2, product_id, product_name, product_category, tax_class, currency, product_price, quantity, created_at, NOW()
+		FROM saved_items
+		WHERE user_id = $1
+		ON CONFLICT (user_id, product_id) DO UPDATE
+		SET quantity = saved_items.quantity + EXCLUDED.quantity,
+		    updated_at = NOW()
+	`, guestID, userID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM saved_items WHERE user_id = $1`, guestID); err != nil {
+		return err
+	}
+
+	var guestCartID int64
+	var guestStatus string
+	err = tx.QueryRow(ctx, `
+		SELECT c.id, `+cartStatus+` FROM carts c WHERE c.user_id = $1 AND c.is_active
+	`, guestID).Scan(&guestCartID, &guestStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Nothing to merge
+		return tx.Commit(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	if guestStatus == string(domain.CartCheckingOut) {
+		return domain.ErrCartLocked
+	}
+
+	// Quantities of the guest's products already in the user's cart, for the events
+	before := make(map[string]int)
+	rows, err := tx.Query(ctx, `
+		SELECT u.product_id::text, u.quantity
+		FROM cart_items g
+		JOIN cart_items u ON u.cart_id = $2 AND u.product_id = g.product_id
+		WHERE g.cart_id = $1
+	`, guestCartID, cartID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var productID string
+		var quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		before[productID] = quantity
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO cart_items (cart_id, product_id, product_name, product_category, tax_class, currency, product_price, quantity, created_at, updated_at)
+		SELECT $2, product_id, product_name, product_category, tax_class, currency, product_price, quantity, NOW(), NOW()
+		FROM cart_items
+		WHERE cart_id = $1
+		ON CONFLICT (cart_id, product_id) DO UPDATE
+		SET quantity = CASE $3::text
+		        WHEN 'sum' THEN cart_items.quantity + EXCLUDED.quantity
+		        WHEN 'max' THEN GREATEST(cart_items.quantity, EXCLUDED.quantity)
+		        ELSE cart_items.quantity
+		    END,
+		    updated_at = NOW()
+		RETURNING id::text, ` + itemColumns + `
+	`
+	rows, err = tx.Query(ctx, query, guestCartID, cartID, string(policy))
+	if err != nil {
+		return err
+	}
+	var merged []*domain.CartItem
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		merged = append(merged, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, item := range merged {
+		if added := item.Quantity - before[item.ProductID]; added > 0 {
+			if err := appendEvent(ctx, tx, cartID, userID, itemAdded(item, added)); err != nil {
+				return err
+			}
+		}
+	}
+
+	var lines, currencies int
+	var currency *string
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(DISTINCT currency), MIN(currency) FROM cart_items WHERE cart_id = $1
+	`, cartID).Scan(&lines, &currencies, &currency)
+	if err != nil {
+		return err
+	}
+	// Only new lines count against the limit, so a cart already past it can
+	// still merge products it holds
+	if r.maxLines > 0 && lines > r.maxLines && len(merged) > len(before) {
+		return domain.ErrCartFull
+	}
+	if currencies > 1 {
+		return domain.ErrCurrencyMismatch
+	}
+	if currency != nil {
+		if _, err := tx.Exec(ctx, `UPDATE carts SET currency = $2 WHERE id = $1`, cartID, *currency); err != nil {
+			return err
+		}
+	}
+
+	// Carry over coupons the guest applied
+	_, err = tx.Exec(ctx, `
+		INSERT INTO cart_coupons (cart_id, promotion_id, created_at)
+		SELECT $2, promotion_id, created_at FROM cart_coupons WHERE cart_id = $1
+		ON CONFLICT (cart_id, promotion_id) DO NOTHING
+	`, guestCartID, cartID)
+	if err != nil {
+		return err
+	}
+
+	// Deleting the guest headers cascades to their items and coupons
+	if _, err := tx.Exec(ctx, `DELETE FROM carts WHERE user_id = $1`, guestID); err != nil {
+		return err
+	}
+
+	// Products now in the cart leave the saved list
+	_, err = tx.Exec(ctx, `
+		DELETE FROM saved_items s
+		USING cart_items i
+		WHERE s.user_id = $1 AND i.cart_id = $2 AND i.product_id = s.product_id
+	`, userID, cartID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
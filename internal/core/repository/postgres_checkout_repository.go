@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresCheckoutRepository implements CheckoutRepository using PostgreSQL with pgx
+type PostgresCheckoutRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresCheckoutRepository creates a new PostgreSQL checkout repository
+func NewPostgresCheckoutRepository(pool *pgxpool.Pool) *PostgresCheckoutRepository {
+	return &PostgresCheckoutRepository{pool: pool}
+}
+
+// Lock stores the snapshot and locks its cart in one transaction. The version
+// bump checks that the cart is still at the version that was priced.
+func (r *PostgresCheckoutRepository) Lock(ctx context.Context, userID string, snapshot *domain.CartSnapshot) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	cartID, err := bumpVersion(domain.WithExpectedVersions(ctx, snapshot.CartVersion), tx, userID)
+	if err != nil {
+		return err
+	}
+	snapshot.CartID = strconv.FormatInt(cartID, 10)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO cart_snapshots (cart_id, user_id, cart_version, currency, subtotal, discount, shipping, tax,
+		                            tax_inclusive, total, item_count, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW())
+		RETURNING id::text, created_at
+	`, cartID, userID, snapshot.CartVersion, snapshot.Currency, snapshot.Subtotal, snapshot.Discount, snapshot.Shipping,
+		snapshot.Tax, snapshot.TaxIncluded, snapshot.Total, snapshot.ItemCount, snapshot.ExpiresAt,
+	).Scan(&snapshot.ID, &snapshot.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range snapshot.Items {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO cart_snapshot_items (snapshot_id, item_id, product_id, product_name, product_category, tax_class,
+			                                 product_price, quantity, subtotal, tax_rate, tax)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, snapshot.ID, item.ID, item.ProductID, item.ProductName, item.ProductCategory, item.TaxClass,
+			item.ProductPrice, item.Quantity, item.Subtotal, item.TaxRate, item.Tax)
+		if err != nil {
+			return err
+		}
+	}
+	for i, d := range snapshot.Discounts {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO cart_snapshot_discounts (snapshot_id, position, code, description, scope, item_id, product_id, amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, snapshot.ID, i, d.Code, d.Description, d.Scope, d.ItemID, d.ProductID, d.Amount)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE carts SET status = 'checking_out', locked_until = $2, checkout_snapshot_id = $3
+		WHERE id = $1
+	`, cartID, snapshot.ExpiresAt, snapshot.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Get loads a snapshot with its items and discounts. A pending snapshot whose
+// lock has lapsed is reported as expired.
+func (r *PostgresCheckoutRepository) Get(ctx context.Context, id string) (*domain.CartSnapshot, error) {
+	var s domain.CartSnapshot
+	var status string
+	var subtotal, discount, shipping, tax, total string
+	err := r.pool.QueryRow(ctx, `
+		SELECT id::text, cart_id::text, user_id, cart_version,
+		       CASE WHEN status = 'pending' AND expires_at <= NOW() THEN 'expired' ELSE status END,
+		       COALESCE(order_id, ''), currency, subtotal::text, discount::text, shipping::text, tax::text,
+		       tax_inclusive, total::text, item_count, created_at, expires_at, resolved_at
+		FROM cart_snapshots
+		WHERE id = $1
+	`, id).Scan(&s.ID, &s.CartID, &s.UserID, &s.CartVersion, &status, &s.OrderID, &s.Currency,
+		&subtotal, &discount, &shipping, &tax, &s.TaxIncluded, &total, &s.ItemCount,
+		&s.CreatedAt, &s.ExpiresAt, &s.ResolvedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Status = domain.SnapshotStatus(status)
+
+	amounts := []struct {
+		dst  *domain.Money
+		text string
+	}{
+		{&s.Subtotal, subtotal}, {&s.Discount, discount}, {&s.Shipping, shipping}, {&s.Tax, tax}, {&s.Total, total},
+	}
+	for _, a := range amounts {
+		if *a.dst, err = snapshotMoney(a.text, s.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.Items, err = r.snapshotItems(ctx, id, s.Currency); err != nil {
+		return nil, err
+	}
+	if s.Discounts, err = r.snapshotDiscounts(ctx, id, s.Currency); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// snapshotItems loads the items of a snapshot in cart order
+func (r *PostgresCheckoutRepository) snapshotItems(ctx context.Context, id, currency string) ([]domain.CartItem, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT item_id::text, product_id::text, product_name, product_category, tax_class,
+		       product_price::text, quantity, subtotal::text, tax_rate, tax::text
+		FROM cart_snapshot_items
+		WHERE snapshot_id = $1
+		ORDER BY item_id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []domain.CartItem{}
+	for rows.Next() {
+		var item domain.CartItem
+		var price, subtotal, tax string
+		err := rows.Scan(&item.ID, &item.ProductID, &item.ProductName, &item.ProductCategory, &item.TaxClass,
+			&price, &item.Quantity, &subtotal, &item.TaxRate, &tax)
+		if err != nil {
+			return nil, err
+		}
+		if item.ProductPrice, err = snapshotMoney(price, currency); err != nil {
+			return nil, err
+		}
+		if item.Subtotal, err = snapshotMoney(subtotal, currency); err != nil {
+			return nil, err
+		}
+		if item.Tax, err = snapshotMoney(tax, currency); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// snapshotDiscounts loads the discounts of a snapshot in the order they were applied
+func (r *PostgresCheckoutRepository) snapshotDiscounts(ctx context.Context, id, currency string) ([]domain.AppliedDiscount, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT code, description, scope, item_id, product_id, amount::text
+		FROM cart_snapshot_discounts
+		WHERE snapshot_id = $1
+		ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	discounts := []domain.AppliedDiscount{}
+	for rows.Next() {
+		var d domain.AppliedDiscount
+		var amount string
+		if err := rows.Scan(&d.Code, &d.Description, &d.Scope, &d.ItemID, &d.ProductID, &amount); err != nil {
+			return nil, err
+		}
+		if d.Amount, err = snapshotMoney(amount, currency); err != nil {
+			return nil, err
+		}
+		discounts = append(discounts, d)
+	}
+	return discounts, rows.Err()
+}
+
+// snapshotMoney parses a NUMERIC column read as text in the snapshot currency
+func snapshotMoney(text, currency string) (domain.Money, error) {
+	m := domain.Money{Currency: currency}
+	err := m.Scan(text)
+	return m, err
+}
+
+// Confirm marks the snapshot confirmed, redeems its coupons, then unlocks and
+// empties its cart. The statement order keeps the transaction on the primary under PgCat.
+func (r *PostgresCheckoutRepository) Confirm(ctx context.Context, id, orderID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var cartID int64
+	var userID string
+	err = tx.QueryRow(ctx, `
+		UPDATE cart_snapshots SET status = 'confirmed', order_id = $2, resolved_at = NOW()
+		WHERE id = $1 AND status = 'pending' AND expires_at > NOW()
+		RETURNING cart_id, user_id
+	`, id, orderID).Scan(&cartID, &userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		status, confirmedFor, err := snapshotState(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if status == domain.SnapshotConfirmed && confirmedFor == orderID {
+			return nil
+		}
+		return domain.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := unlockCart(ctx, tx, cartID, id); err != nil {
+		return err
+	}
+
+	// Reservations go with the items
+	result, err := tx.Exec(ctx, `DELETE FROM cart_items WHERE cart_id = $1`, cartID)
+	if err != nil {
+		return err
+	}
+	if err := redeemCoupons(ctx, tx, cartID, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM cart_coupons WHERE cart_id = $1`, cartID); err != nil {
+		return err
+	}
+
+	event := domain.CartCheckoutConfirmed{SnapshotID: id, OrderID: orderID, ItemCount: int(result.RowsAffected())}
+	if err := appendEvent(ctx, tx, cartID, userID, event); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Cancel marks the snapshot cancelled and unlocks its cart as it was
+func (r *PostgresCheckoutRepository) Cancel(ctx context.Context, id string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var cartID int64
+	err = tx.QueryRow(ctx, `
+		UPDATE cart_snapshots SET status = 'cancelled', resolved_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING cart_id
+	`, id).Scan(&cartID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		status, _, err := snapshotState(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if status == domain.SnapshotCancelled {
+			return nil
+		}
+		return domain.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	// A lapsed lock may already have been released, or the cart deleted since
+	if err := unlockCart(ctx, tx, cartID, id); err != nil && !errors.Is(err, domain.ErrConflict) {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// redeemCoupons turns the cart's hold on each coupon that discounted the
+// snapshot into a redemption. The holds were counted against the usage
+// limits when the coupons were applied, so redeeming cannot exceed them.
+func redeemCoupons(ctx context.Context, tx pgx.Tx, cartID int64, snapshotID string) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE promotions p SET usage_count = usage_count + 1, updated_at = NOW()
+		FROM cart_coupons cc
+		WHERE cc.promotion_id = p.id AND cc.cart_id = $1
+		  AND UPPER(p.code) IN (SELECT UPPER(code) FROM cart_snapshot_discounts WHERE snapshot_id = $2)
+	`, cartID, snapshotID)
+	return err
+}
+
+// snapshotState returns the stored status and order ID of a snapshot, or ErrNotFound
+func snapshotState(ctx context.Context, tx pgx.Tx, id string) (domain.SnapshotStatus, string, error) {
+	var status, orderID string
+	err := tx.QueryRow(ctx, `
+		SELECT status, COALESCE(order_id, '') FROM cart_snapshots WHERE id = $1
+	`, id).Scan(&status, &orderID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", domain.ErrNotFound
+	}
+	return domain.SnapshotStatus(status), orderID, err
+}
+
+// unlockCart releases the lock snapshotID holds on cartID and bumps the cart
+// version. Returns ErrConflict if the snapshot no longer holds the lock.
+func unlockCart(ctx context.Context, tx pgx.Tx, cartID int64, snapshotID string) error {
+	result, err := tx.Exec(ctx, `
+		UPDATE carts
+		SET status = CASE WHEN status = 'checking_out' THEN 'active' ELSE status END,
+		    locked_until = NULL, checkout_snapshot_id = NULL,
+		    version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND checkout_snapshot_id = $2
+	`, cartID, snapshotID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresIdempotencyRepository implements IdempotencyRepository using PostgreSQL with pgx
+type PostgresIdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresIdempotencyRepository creates a new PostgreSQL idempotency key repository
+func NewPostgresIdempotencyRepository(pool *pgxpool.Pool) *PostgresIdempotencyRepository {
+	return &PostgresIdempotencyRepository{pool: pool}
+}
+
+// Reserve inserts the key, taking over an expired record for the same key.
+// The insert runs first so PgCat keeps the transaction on the primary, where
+// the existing record is then guaranteed to be visible.
+func (r *PostgresIdempotencyRepository) Reserve(ctx context.Context, rec *domain.IdempotencyRecord) (*domain.IdempotencyRecord, bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+		INSERT INTO idempotency_keys (owner_id, idempotency_key, fingerprint, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (owner_id, idempotency_key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+		    status_code = NULL,
+		    response_headers = NULL,
+		    response_body = NULL,
+		    expires_at = EXCLUDED.expires_at,
+		    created_at = NOW()
+		WHERE idempotency_keys.expires_at <= NOW()
+		RETURNING true
+	`
+	var reserved bool
+	err = tx.QueryRow(ctx, query, rec.OwnerID, rec.Key, rec.Fingerprint, rec.ExpiresAt).Scan(&reserved)
+	if err == nil {
+		return nil, true, tx.Commit(ctx)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, err
+	}
+
+	existing := domain.IdempotencyRecord{OwnerID: rec.OwnerID, Key: rec.Key}
+	var status *int
+	err = tx.QueryRow(ctx, `
+		SELECT fingerprint, status_code, response_headers, response_body, expires_at
+		FROM idempotency_keys
+		WHERE owner_id = $1 AND idempotency_key = $2
+	`, rec.OwnerID, rec.Key).Scan(&existing.Fingerprint, &status, &existing.Headers, &existing.Body, &existing.ExpiresAt)
+	if err != nil {
+		return nil, false, err
+	}
+	if status != nil {
+		existing.StatusCode = *status
+	}
+	return &existing, false, tx.Commit(ctx)
+}
+
+// Complete stores the response of a reserved request
+func (r *PostgresIdempotencyRepository) Complete(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $3, response_headers = $4, response_body = $5
+		WHERE owner_id = $1 AND idempotency_key = $2 AND fingerprint = $6
+	`
+
+	result, err := r.pool.Exec(ctx, query, rec.OwnerID, rec.Key, rec.StatusCode, rec.Headers, rec.Body, rec.Fingerprint)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// Release deletes an unfinished reservation
+func (r *PostgresIdempotencyRepository) Release(ctx context.Context, ownerID, key string) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE owner_id = $1 AND idempotency_key = $2 AND status_code IS NULL
+	`
+	_, err := r.pool.Exec(ctx, query, ownerID, key)
+	return err
+}
+
+// DeleteExpired removes expired records
+func (r *PostgresIdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
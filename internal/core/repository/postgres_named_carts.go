@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// summaryColumns is the column list scanned by scanSummary
+const summaryColumns = `
+	c.id::text, c.name, c.kind, c.is_active, ` + cartStatus + `, c.version, c.currency,
+	(SELECT COALESCE(SUM(i.quantity), 0) FROM cart_items i WHERE i.cart_id = c.id),
+	c.updated_at`
+
+// ListCarts returns the user's carts and wishlists, active cart first
+func (r *PostgresCartRepository) ListCarts(ctx context.Context, userID string) ([]domain.CartSummary, error) {
+	query := `SELECT ` + summaryColumns + `
+		FROM carts c
+		WHERE c.user_id = $1
+		ORDER BY c.is_active DESC, c.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var carts []domain.CartSummary
+	for rows.Next() {
+		summary, err := scanSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		carts = append(carts, *summary)
+	}
+	return carts, rows.Err()
+}
+
+// CreateCart creates an inactive named cart or wishlist
+func (r *PostgresCartRepository) CreateCart(ctx context.Context, userID, name string, kind domain.CartKind) (*domain.CartSummary, error) {
+	query := `
+		WITH c AS (
+			INSERT INTO carts (user_id, name, kind, created_at, updated_at)
+			VALUES ($1, $2, $3, NOW(), NOW())
+			RETURNING *
+		)
+		SELECT ` + summaryColumns + ` FROM c
+	`
+	return scanSummary(r.pool.QueryRow(ctx, query, userID, name, string(kind)))
+}
+
+// DeleteCart deletes a cart with its items and coupons
+func (r *PostgresCartRepository) DeleteCart(ctx context.Context, userID, cartID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Locks the cart and checks If-Match like any other mutation
+	id, err := bumpVersion(domain.WithCart(ctx, cartID), tx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Items cascade with the cart; they are counted first for the event
+	var itemCount int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM cart_items WHERE cart_id = $1`, id).Scan(&itemCount)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM carts WHERE id = $1`, id); err != nil {
+		return err
+	}
+	if itemCount > 0 {
+		if err := appendEvent(ctx, tx, id, userID, domain.CartCleared{ItemCount: itemCount}); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetActiveCart makes cartID the user's active cart
+func (r *PostgresCartRepository) SetActiveCart(ctx context.Context, userID, cartID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// The old pointer is cleared first: the unique index on active carts is checked per row
+	_, err = tx.Exec(ctx, `
+		UPDATE carts SET is_active = FALSE
+		WHERE user_id = $1 AND is_active AND id <> $2
+	`, userID, cartID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE carts SET is_active = TRUE, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+	`, cartID, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrCartNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// scanSummary scans a row selected with summaryColumns
+func scanSummary(row pgx.Row) (*domain.CartSummary, error) {
+	var s domain.CartSummary
+	var kind, status string
+	err := row.Scan(&s.ID, &s.Name, &kind, &s.Active, &status, &s.Version, &s.Currency, &s.ItemCount, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	s.Kind = domain.CartKind(kind)
+	s.Status = domain.CartStatus(status)
+	return &s, nil
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// appendEvent writes an event for cartID to the outbox within the mutation's transaction
+func appendEvent(ctx context.Context, tx pgx.Tx, cartID int64, userID string, payload domain.EventPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode %s event: %w", payload.EventType(), err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO outbox (event_type, cart_id, user_id, payload, occurred_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+	`, string(payload.EventType()), cartID, userID, string(data))
+	return err
+}
+
+// PostgresOutboxRepository implements OutboxRepository using PostgreSQL with pgx
+type PostgresOutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresOutboxRepository creates a new PostgreSQL outbox repository
+func NewPostgresOutboxRepository(pool *pgxpool.Pool) *PostgresOutboxRepository {
+	return &PostgresOutboxRepository{pool: pool}
+}
+
+// Claim leases due events by moving next_attempt_at past the lease. The
+// statement starts with UPDATE so PgCat routes it to the primary, and rows being
+// claimed by another relay are skipped.
+func (r *PostgresOutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.Event, error) {
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1,
+		    next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE published_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_type, cart_id::text, user_id, occurred_at, payload::text, attempts
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type claimed struct {
+		id    int64
+		event domain.Event
+	}
+	var claims []claimed
+	for rows.Next() {
+		var c claimed
+		var eventType, payload string
+		err := rows.Scan(&c.id, &eventType, &c.event.CartID, &c.event.UserID, &c.event.OccurredAt, &payload, &c.event.Attempts)
+		if err != nil {
+			return nil, err
+		}
+		c.event.ID = strconv.FormatInt(c.id, 10)
+		c.event.Type = domain.EventType(eventType)
+		c.event.Data = json.RawMessage(payload)
+		claims = append(claims, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the subquery order
+	slices.SortFunc(claims, func(a, b claimed) int { return cmp.Compare(a.id, b.id) })
+	events := make([]domain.Event, len(claims))
+	for i, c := range claims {
+		events[i] = c.event
+	}
+	return events, nil
+}
+
+// MarkPublished records the events as delivered
+func (r *PostgresOutboxRepository) MarkPublished(ctx context.Context, ids []string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE outbox SET published_at = NOW(), last_error = NULL
+		WHERE id = ANY($1::text[]::bigint[])
+	`, ids)
+	return err
+}
+
+// Retry schedules the events for another attempt
+func (r *PostgresOutboxRepository) Retry(ctx context.Context, ids []string, retryAt time.Time, cause string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE outbox SET next_attempt_at = $2, last_error = $3
+		WHERE id = ANY($1::text[]::bigint[]) AND published_at IS NULL
+	`, ids, retryAt, cause)
+	return err
+}
+
+// DeletePublished removes events published before the given time
+func (r *PostgresOutboxRepository) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.pool.Exec(ctx, `DELETE FROM outbox WHERE published_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
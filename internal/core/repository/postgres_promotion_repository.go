@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// promotionColumns is the column list scanned by scanPromotion
+const promotionColumns = `
+	p.id, p.code, p.description, p.type, p.percent_off, p.currency, p.amount_off,
+	p.buy_quantity, p.get_quantity, p.product_ids, p.categories, p.currency, p.min_subtotal,
+	p.usage_limit, p.usage_count, p.starts_at, p.ends_at, p.active`
+
+// PostgresPromotionRepository implements PromotionRepository using PostgreSQL with pgx
+type PostgresPromotionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresPromotionRepository creates a new PostgreSQL promotion repository
+func NewPostgresPromotionRepository(pool *pgxpool.Pool) *PostgresPromotionRepository {
+	return &PostgresPromotionRepository{pool: pool}
+}
+
+// FindByCode retrieves a promotion by its coupon code (case-insensitive)
+func (r *PostgresPromotionRepository) FindByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	query := `SELECT ` + promotionColumns + `
+		FROM promotions p
+		WHERE UPPER(p.code) = UPPER($1)
+	`
+
+	p, err := scanPromotion(r.pool.QueryRow(ctx, query, code))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListForCart returns the promotions attached to the user's cart
+func (r *PostgresPromotionRepository) ListForCart(ctx context.Context, userID string) ([]domain.Promotion, error) {
+	cond, args := cartCondition(ctx, userID)
+	query := `SELECT ` + promotionColumns + `
+		FROM cart_coupons cc
+		JOIN carts c ON c.id = cc.cart_id
+		JOIN promotions p ON p.id = cc.promotion_id
+		WHERE ` + cond + `
+		ORDER BY cc.created_at, p.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []domain.Promotion
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, *p)
+	}
+	return promotions, rows.Err()
+}
+
+// Attach adds a coupon to the user's cart and checks the coupon's usage limit
+// in the same transaction, so the attach is undone when no use is left
+func (r *PostgresPromotionRepository) Attach(ctx context.Context, userID, promotionID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	cartID, err := bumpVersion(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO cart_coupons (cart_id, promotion_id, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (cart_id, promotion_id) DO NOTHING
+	`
+	result, err := tx.Exec(ctx, query, cartID, promotionID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		// Already attached; its use is already counted
+		return nil
+	}
+
+	if err := checkCouponUses(ctx, tx, promotionID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// checkCouponUses returns ErrCouponExhausted if the carts holding the promotion
+// and the orders that redeemed it exceed its usage limit. The promotion row is
+// locked first so concurrent attaches count each other's carts.
+func checkCouponUses(ctx context.Context, tx pgx.Tx, promotionID string) error {
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM promotions WHERE id = $1 FOR UPDATE`, promotionID); err != nil {
+		return err
+	}
+
+	var exhausted bool
+	err := tx.QueryRow(ctx, `
+		SELECT p.usage_limit > 0
+		   AND p.usage_count + (SELECT COUNT(*) FROM cart_coupons c WHERE c.promotion_id = p.id) > p.usage_limit
+		FROM promotions p
+		WHERE p.id = $1
+	`, promotionID).Scan(&exhausted)
+	if err != nil {
+		return err
+	}
+	if exhausted {
+		return domain.ErrCouponExhausted
+	}
+	return nil
+}
+
+// Detach removes a coupon from the user's cart
+func (r *PostgresPromotionRepository) Detach(ctx context.Context, userID, code string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	cartID, err := bumpVersion(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		DELETE FROM cart_coupons c
+		USING promotions p
+		WHERE p.id = c.promotion_id AND c.cart_id = $1 AND UPPER(p.code) = UPPER($2)
+	`
+
+	result, err := tx.Exec(ctx, query, cartID, code)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// scanPromotion scans a row selected with promotionColumns
+func scanPromotion(row pgx.Row) (*domain.Promotion, error) {
+	var p domain.Promotion
+	var promoType string
+	// currency is scanned before the amounts so Money.Scan uses the right exponent
+	err := row.Scan(&p.ID, &p.Code, &p.Description, &promoType, &p.PercentOff,
+		&p.AmountOff.Currency, &p.AmountOff,
+		&p.BuyQuantity, &p.GetQuantity, &p.ProductIDs, &p.Categories,
+		&p.MinSubtotal.Currency, &p.MinSubtotal,
+		&p.UsageLimit, &p.UsageCount, &p.StartsAt, &p.EndsAt, &p.Active)
+	if err != nil {
+		return nil, err
+	}
+	p.Type = domain.PromotionType(promoType)
+	return &p, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresReservationRepository implements ReservationRepository using PostgreSQL with pgx
+type PostgresReservationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresReservationRepository creates a new PostgreSQL inventory reservation repository
+func NewPostgresReservationRepository(pool *pgxpool.Pool) *PostgresReservationRepository {
+	return &PostgresReservationRepository{pool: pool}
+}
+
+// Refresh upserts one reservation per item of the cart. Reservations of removed
+// items are already gone: they cascade with the cart_items rows.
+func (r *PostgresReservationRepository) Refresh(ctx context.Context, userID string, expiresAt time.Time) error {
+	cond, args := cartCondition(ctx, userID)
+	query := fmt.Sprintf(`
+		INSERT INTO inventory_reservations (cart_item_id, product_id, quantity, expires_at, created_at, updated_at)
+		SELECT i.id, i.product_id, i.quantity, $%d, NOW(), NOW()
+		FROM cart_items i
+		JOIN carts c ON c.id = i.cart_id
+		WHERE %s AND c.kind = 'cart'
+		ON CONFLICT (cart_item_id) DO UPDATE
+		SET quantity = EXCLUDED.quantity,
+		    expires_at = EXCLUDED.expires_at,
+		    updated_at = NOW()
+	`, len(args)+1, cond)
+
+	_, err := r.pool.Exec(ctx, query, append(args, expiresAt)...)
+	return err
+}
+
+// HeldByOthers sums the unexpired reservations of productIDs held by other users.
+// Reservations in the user's other carts are left out as well: the user is not
+// competing with themselves for the stock.
+func (r *PostgresReservationRepository) HeldByOthers(ctx context.Context, userID string, productIDs []string) (map[string]int, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT r.product_id::text, SUM(r.quantity)
+		FROM inventory_reservations r
+		JOIN cart_items i ON i.id = r.cart_item_id
+		JOIN carts c ON c.id = i.cart_id
+		WHERE r.product_id = ANY($2::text[]::int[])
+		  AND r.expires_at > NOW()
+		  AND c.user_id <> $1
+		GROUP BY r.product_id
+	`, userID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	held := make(map[string]int)
+	for rows.Next() {
+		var productID string
+		var quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			return nil, err
+		}
+		held[productID] = quantity
+	}
+	return held, rows.Err()
+}
+
+// DeleteExpired removes expired reservations
+func (r *PostgresReservationRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := r.pool.Exec(ctx, `DELETE FROM inventory_reservations WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
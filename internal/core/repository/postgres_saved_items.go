@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// itemColumns is the product snapshot shared by cart_items and saved_items,
+// in the order scanned by scanItem
+const itemColumns = `product_id::text, product_name, product_category, tax_class, currency, product_price, quantity`
+
+// scanItem scans a row selected with id followed by itemColumns
+func scanItem(row pgx.Row) (*domain.CartItem, error) {
+	var item domain.CartItem
+	// currency is scanned before product_price so Money.Scan uses the right exponent
+	err := row.Scan(&item.ID, &item.ProductID, &item.ProductName, &item.ProductCategory, &item.TaxClass,
+		&item.ProductPrice.Currency, &item.ProductPrice, &item.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ListSaved returns the user's saved-for-later items, oldest first
+func (r *PostgresCartRepository) ListSaved(ctx context.Context, userID string) ([]domain.CartItem, error) {
+	query := `SELECT id::text, ` + itemColumns + `
+		FROM saved_items
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []domain.CartItem
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// MoveItem moves an item between the cart and the saved list in one transaction
+func (r *PostgresCartRepository) MoveItem(ctx context.Context, userID, itemID string, to domain.ItemList) (*domain.CartItem, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Both directions change the cart, so the version bump comes first
+	cartID, err := bumpVersion(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var item *domain.CartItem
+	switch to {
+	case domain.ListSaved:
+		item, err = moveToSaved(ctx, tx, cartID, userID, itemID)
+	case domain.ListCart:
+		item, err = moveToCart(ctx, tx, cartID, userID, itemID, r.maxLines)
+	default:
+		return nil, fmt.Errorf("move to list %q: %w", to, domain.ErrInvalidInput)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// moveToSaved moves cart item itemID to the saved list
+func moveToSaved(ctx context.Context, tx pgx.Tx, cartID int64, userID, itemID string) (*domain.CartItem, error) {
+	item, err := scanItem(tx.QueryRow(ctx, `
+		DELETE FROM cart_items WHERE id = $1 AND cart_id = $2
+		RETURNING id::text, `+itemColumns, itemID, cartID))
+	if err != nil {
+		return nil, err
+	}
+	event := domain.CartItemRemoved{ItemID: item.ID, ProductID: item.ProductID, Quantity: item.Quantity}
+	if err := appendEvent(ctx, tx, cartID, userID, event); err != nil {
+		return nil, err
+	}
+	return item, upsertSavedItem(ctx, tx, userID, item)
+}
+
+// moveToCart moves saved item itemID into the cart
+func moveToCart(ctx context.Context, tx pgx.Tx, cartID int64, userID, itemID string, maxLines int) (*domain.CartItem, error) {
+	item, err := scanItem(tx.QueryRow(ctx, `
+		DELETE FROM saved_items WHERE id = $1 AND user_id = $2
+		RETURNING id::text, `+itemColumns, itemID, userID))
+	if err != nil {
+		return nil, err
+	}
+	added := item.Quantity
+	if err := upsertCartItem(ctx, tx, cartID, item, maxLines); err != nil {
+		return nil, err
+	}
+	return item, appendEvent(ctx, tx, cartID, userID, itemAdded(item, added))
+}
+
+// RemoveSaved deletes an item from the saved list
+func (r *PostgresCartRepository) RemoveSaved(ctx context.Context, userID, itemID string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM saved_items WHERE id = $1 AND user_id = $2`, itemID, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// upsertSavedItem inserts item into the saved list with the same semantics as
+// upsertCartItem: quantities are summed when the product is already saved.
+func upsertSavedItem(ctx context.Context, tx pgx.Tx, userID string, item *domain.CartItem) error {
+	query := `
+		INSERT INTO saved_items (user_id, product_id, product_name, product_category, tax_class, currency, product_price, quantity, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		ON CONFLICT (user_id, product_id) DO UPDATE
+		SET quantity = saved_items.quantity + EXCLUDED.quantity,
+		    updated_at = NOW()
+		RETURNING id, quantity
+	`
+	return tx.QueryRow(ctx, query, userID, item.ProductID, item.ProductName, item.ProductCategory, item.TaxClass,
+		item.ProductPrice.Currency, item.ProductPrice, item.Quantity).Scan(&item.ID, &item.Quantity)
+}
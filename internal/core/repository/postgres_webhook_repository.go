@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// deliveryColumns are the webhook_deliveries columns scanned by scanDelivery
+const deliveryColumns = `d.id::text, d.subscription_id::text, d.event_id::text, d.event_type, d.status, d.attempts,
+	COALESCE(d.last_status_code, 0), COALESCE(d.last_error, ''), d.next_attempt_at, d.delivered_at, d.created_at`
+
+// PostgresWebhookRepository implements WebhookRepository using PostgreSQL with pgx
+type PostgresWebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresWebhookRepository creates a new PostgreSQL webhook repository
+func NewPostgresWebhookRepository(pool *pgxpool.Pool) *PostgresWebhookRepository {
+	return &PostgresWebhookRepository{pool: pool}
+}
+
+// CreateSubscription stores a new subscription
+func (r *PostgresWebhookRepository) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	eventTypes := make([]string, len(sub.EventTypes))
+	for i, t := range sub.EventTypes {
+		eventTypes[i] = string(t)
+	}
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO webhook_subscriptions (url, event_types, secret, description, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id::text, created_at
+	`, sub.URL, eventTypes, sub.Secret, sub.Description, sub.Active).Scan(&sub.ID, &sub.CreatedAt)
+}
+
+// ListSubscriptions returns every subscription, oldest first
+func (r *PostgresWebhookRepository) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id::text, url, event_types, description, active, created_at
+		FROM webhook_subscriptions
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []domain.WebhookSubscription{}
+	for rows.Next() {
+		var sub domain.WebhookSubscription
+		var eventTypes []string
+		if err := rows.Scan(&sub.ID, &sub.URL, &eventTypes, &sub.Description, &sub.Active, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		sub.EventTypes = make([]domain.EventType, len(eventTypes))
+		for i, t := range eventTypes {
+			sub.EventTypes[i] = domain.EventType(t)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteSubscription deletes a subscription; its deliveries cascade
+func (r *PostgresWebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Enqueue fans the event out to matching subscriptions in one INSERT ... SELECT.
+// The unique (subscription_id, event_id) key makes it safe to repeat when the
+// outbox relay redelivers the event.
+func (r *PostgresWebhookRepository) Enqueue(ctx context.Context, event domain.Event) (int, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("encode event %s: %w", event.ID, err)
+	}
+	result, err := r.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT id, $1::text::bigint, $2::text, $3::jsonb
+		FROM webhook_subscriptions
+		WHERE active AND (cardinality(event_types) = 0 OR $2::text = ANY(event_types))
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`, event.ID, string(event.Type), string(payload))
+	if err != nil {
+		return 0, err
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// ClaimDeliveries leases due deliveries by moving next_attempt_at past the
+// lease, skipping rows another dispatcher is claiming. Deliveries of deleted or
+// deactivated subscriptions are not claimed.
+func (r *PostgresWebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1,
+		    next_attempt_at = NOW() + make_interval(secs => $2),
+		    updated_at = NOW()
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id AND d.id IN (
+			SELECT wd.id FROM webhook_deliveries wd
+			JOIN webhook_subscriptions ws ON ws.id = wd.subscription_id AND ws.active
+			WHERE wd.status = 'pending' AND wd.next_attempt_at <= NOW()
+			ORDER BY wd.id
+			LIMIT $1
+			FOR UPDATE OF wd SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns + `, d.payload::text, s.url, s.secret
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []domain.WebhookDelivery
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		var payload string
+		if err := scanDelivery(rows, &d, &payload, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		d.Payload = json.RawMessage(payload)
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the subquery order
+	slices.SortFunc(deliveries, func(a, b domain.WebhookDelivery) int {
+		x, _ := strconv.ParseInt(a.ID, 10, 64)
+		y, _ := strconv.ParseInt(b.ID, 10, 64)
+		return cmp.Compare(x, y)
+	})
+	return deliveries, nil
+}
+
+// RecordResult stores the outcome of a delivery attempt
+func (r *PostgresWebhookRepository) RecordResult(ctx context.Context, id string, result domain.DeliveryResult) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2,
+		    last_status_code = NULLIF($3::int, 0),
+		    last_error = NULLIF($4::text, ''),
+		    next_attempt_at = CASE WHEN $2 = 'pending' THEN $5 ELSE next_attempt_at END,
+		    delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() ELSE delivered_at END,
+		    updated_at = NOW()
+		WHERE id = $1
+	`, id, string(result.Status), result.StatusCode, result.Error, result.RetryAt)
+	return err
+}
+
+// ListDeliveries returns the latest deliveries of a subscription
+func (r *PostgresWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, status domain.DeliveryStatus, limit int) ([]domain.WebhookDelivery, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM webhook_subscriptions WHERE id = $1)`, subscriptionID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries d
+		WHERE d.subscription_id = $1 AND ($2::text = '' OR d.status = $2::text)
+		ORDER BY d.id DESC
+		LIMIT $3
+	`, subscriptionID, string(status), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		if err := scanDelivery(rows, &d); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// Redeliver resets a finished delivery to pending, due now
+func (r *PostgresWebhookRepository) Redeliver(ctx context.Context, subscriptionID, deliveryID string) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND subscription_id = $2 AND status <> 'pending'
+	`, deliveryID, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// scanDelivery scans deliveryColumns, followed by extra destinations, into d
+func scanDelivery(row pgx.Row, d *domain.WebhookDelivery, extra ...any) error {
+	var eventType, status string
+	var nextAttemptAt time.Time
+	dest := append([]any{
+		&d.ID, &d.SubscriptionID, &d.EventID, &eventType, &status, &d.Attempts,
+		&d.LastStatusCode, &d.LastError, &nextAttemptAt, &d.DeliveredAt, &d.CreatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	d.EventType = domain.EventType(eventType)
+	d.Status = domain.DeliveryStatus(status)
+	if d.Status == domain.DeliveryPending {
+		d.NextAttemptAt = &nextAttemptAt
+	}
+	return nil
+}
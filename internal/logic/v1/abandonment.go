@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/cart-service/middleware"
+	"github.com/duynhne/pkg/logger/clog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sweepBatchSize bounds the carts processed per statement, keeping row locks short
+const sweepBatchSize = 500
+
+var cartLifecycleTransitions = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cart_lifecycle_transitions_total",
+		Help: "Carts abandoned or deleted by the abandonment sweeper",
+	},
+	[]string{"transition"}, // abandoned, deleted
+)
+
+// AbandonmentSweeper marks carts abandoned after an idle period and deletes them
+// once the retention period has passed without activity. The repository writes a
+// cart.abandoned event to the outbox with each abandoned cart, so the event relay
+// delivers it at least once. Several replicas may run it at once: the repository
+// skips carts locked by another sweeper.
+type AbandonmentSweeper struct {
+	repo      domain.CartLifecycleRepository
+	idleAfter time.Duration
+	retention time.Duration
+	interval  time.Duration
+}
+
+// NewAbandonmentSweeper creates a sweeper that runs every interval
+func NewAbandonmentSweeper(repo domain.CartLifecycleRepository, idleAfter, retention, interval time.Duration) *AbandonmentSweeper {
+	return &AbandonmentSweeper{
+		repo:      repo,
+		idleAfter: idleAfter,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// Run sweeps every interval until ctx is cancelled
+func (s *AbandonmentSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+				clog.WarnContext(ctx, "Abandoned cart sweep failed", "error", err)
+			}
+		}
+	}
+}
+
+// Sweep abandons every cart idle for longer than the idle period and deletes
+// every expired cart, in batches
+func (s *AbandonmentSweeper) Sweep(ctx context.Context) error {
+	ctx, span := middleware.StartSpan(ctx, "cart.sweep", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	abandoned, err := s.abandonIdle(ctx)
+	span.SetAttributes(attribute.Int("carts.abandoned", abandoned))
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	var deleted int64
+	for {
+		n, err := s.repo.DeleteExpired(ctx, sweepBatchSize)
+		deleted += n
+		cartLifecycleTransitions.WithLabelValues("deleted").Add(float64(n))
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+		if n < sweepBatchSize {
+			break
+		}
+	}
+	span.SetAttributes(attribute.Int64("carts.deleted", deleted))
+
+	if abandoned > 0 || deleted > 0 {
+		clog.InfoContext(ctx, "Abandoned cart sweep complete", "abandoned", abandoned, "deleted", deleted)
+	}
+	return nil
+}
+
+// abandonIdle marks idle carts abandoned batch by batch
+func (s *AbandonmentSweeper) abandonIdle(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		now := time.Now()
+		carts, err := s.repo.MarkAbandoned(ctx, now.Add(-s.idleAfter), now.Add(s.retention), sweepBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += len(carts)
+		cartLifecycleTransitions.WithLabelValues("abandoned").Add(float64(len(carts)))
+		if len(carts) < sweepBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+// mockLifecycleRepository serves idle carts and expired carts in batches
+type mockLifecycleRepository struct {
+	idle, expired int
+	idleBefore    time.Time
+	expiresAt     time.Time
+}
+
+func (m *mockLifecycleRepository) MarkAbandoned(ctx context.Context, idleBefore, expiresAt time.Time, limit int) ([]domain.AbandonedCart, error) {
+	m.idleBefore, m.expiresAt = idleBefore, expiresAt
+	var carts []domain.AbandonedCart
+	for ; m.idle > 0 && len(carts) < limit; m.idle-- {
+		carts = append(carts, domain.AbandonedCart{CartID: strconv.Itoa(m.idle)})
+	}
+	return carts, nil
+}
+
+func (m *mockLifecycleRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
+	n := min(m.expired, limit)
+	m.expired -= n
+	return int64(n), nil
+}
+
+func TestAbandonmentSweep(t *testing.T) {
+	repo := &mockLifecycleRepository{idle: sweepBatchSize + 3, expired: 2*sweepBatchSize + 1}
+	sweeper := NewAbandonmentSweeper(repo, 72*time.Hour, 30*24*time.Hour, time.Minute)
+
+	before := time.Now()
+	if err := sweeper.Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+
+	if repo.idle != 0 || repo.expired != 0 {
+		t.Errorf("left %d idle and %d expired carts, want every batch processed", repo.idle, repo.expired)
+	}
+	after := time.Now()
+	if repo.idleBefore.Before(before.Add(-72*time.Hour)) || repo.idleBefore.After(after.Add(-72*time.Hour)) {
+		t.Errorf("idleBefore = %v, want 72h before the sweep", repo.idleBefore)
+	}
+	if repo.expiresAt.Before(before.Add(30*24*time.Hour)) || repo.expiresAt.After(after.Add(30*24*time.Hour)) {
+		t.Errorf("expiresAt = %v, want 30 days after the sweep", repo.expiresAt)
+	}
+}
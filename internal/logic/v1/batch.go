@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/cart-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultMaxBatchOperations bounds the operations in one batch
+const DefaultMaxBatchOperations = 50
+
+// WithMaxBatchOperations overrides the maximum operations per batch
+func WithMaxBatchOperations(n int) CartServiceOption {
+	return func(s *CartService) {
+		s.maxBatchOps = n
+	}
+}
+
+// BatchItems validates and applies req's operations to the cart in order, in
+// one transaction, and returns the outcome of each with the resulting cart.
+// An atomic batch changes nothing unless every operation succeeds; a best-effort
+// batch applies the operations that succeed. Failures of single operations are
+// reported in their results; the error is for failures of the whole batch.
+func (s *CartService) BatchItems(ctx context.Context, userID string, req domain.BatchItemsRequest) (*domain.BatchItemsResult, error) {
+	ctx, span := middleware.StartSpan(ctx, "cart.batch", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", userID),
+		attribute.Int("batch.operations", len(req.Operations)),
+	))
+	defer span.End()
+
+	if len(req.Operations) > s.maxBatchOps {
+		return nil, fmt.Errorf("%d operations, at most %d allowed: %w", len(req.Operations), s.maxBatchOps, ErrBatchTooLarge)
+	}
+	atomic := req.Mode != domain.BatchBestEffort
+
+	results := make([]domain.BatchOpResult, len(req.Operations))
+	ops := make([]domain.BatchOp, 0, len(req.Operations))
+	positions := make([]int, 0, len(req.Operations)) // Index in results of each op in ops
+	failed := false
+	for i, operation := range req.Operations {
+		results[i].Op = operation.Op
+		op, err := s.batchOp(ctx, userID, operation)
+		if err != nil {
+			results[i].Status, results[i].Err = domain.BatchOpFailed, err
+			failed = true
+			continue
+		}
+		ops = append(ops, op)
+		positions = append(positions, i)
+	}
+
+	if len(ops) > 0 && !(atomic && failed) {
+		// Call repository
+		errs, err := s.cartRepo.ApplyBatch(ctx, userID, ops, atomic)
+		if err != nil {
+			span.RecordError(err)
+			return nil, mutationError(err)
+		}
+		for j, opErr := range errs {
+			result := &results[positions[j]]
+			if opErr != nil {
+				result.Status, result.Err = domain.BatchOpFailed, batchOpError(opErr)
+				failed = true
+				continue
+			}
+			result.Status = domain.BatchOpApplied
+			result.Item = ops[j].Item
+		}
+	}
+
+	batch := &domain.BatchItemsResult{Results: results}
+	for i := range results {
+		switch {
+		case results[i].Status == "", atomic && failed && results[i].Status == domain.BatchOpApplied:
+			// Not attempted, or rolled back with the rest of an atomic batch
+			results[i].Status, results[i].Item = domain.BatchOpNotApplied, nil
+		case results[i].Status == domain.BatchOpApplied:
+			batch.Applied = true
+		}
+	}
+	if batch.Applied {
+		s.holdStock(ctx, userID)
+	}
+
+	cart, err := s.GetCart(ctx, userID, domain.GetCartRequest{})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	batch.Cart = cart
+
+	span.SetAttributes(attribute.Bool("batch.applied", batch.Applied), attribute.Bool("batch.failed", failed))
+	return batch, nil
+}
+
+// batchOp validates one batch operation like the single-item method of its type
+func (s *CartService) batchOp(ctx context.Context, userID string, operation domain.BatchOperation) (domain.BatchOp, error) {
+	switch operation.Op {
+	case domain.BatchAdd:
+		if operation.Item == nil {
+			return domain.BatchOp{}, domain.ErrInvalidInput
+		}
+		item, err := s.newCartItem(ctx, *operation.Item)
+		if err != nil {
+			return domain.BatchOp{}, err
+		}
+		if s.inventory != nil {
+			if err := s.checkAddStock(ctx, userID, item.ProductID, item.Quantity); err != nil {
+				return domain.BatchOp{}, err
+			}
+		}
+		return domain.BatchOp{Type: domain.BatchAdd, Item: &item}, nil
+
+	case domain.BatchUpdate:
+		if operation.Quantity <= 0 {
+			return domain.BatchOp{}, ErrInvalidQuantity
+		}
+		if s.inventory != nil {
+			if err := s.checkUpdateStock(ctx, userID, operation.ItemID, operation.Quantity); err != nil {
+				return domain.BatchOp{}, err
+			}
+		}
+		return domain.BatchOp{Type: domain.BatchUpdate, ItemID: operation.ItemID, Quantity: operation.Quantity}, nil
+
+	case domain.BatchRemove:
+		return domain.BatchOp{Type: domain.BatchRemove, ItemID: operation.ItemID}, nil
+	}
+	return domain.BatchOp{}, domain.ErrInvalidInput
+}
+
+// batchOpError translates the repository error of one batch operation
+func batchOpError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return ErrCartItemNotFound
+	case errors.Is(err, domain.ErrCurrencyMismatch):
+		return ErrMixedCurrency
+	}
+	return err
+}
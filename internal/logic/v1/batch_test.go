@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+func TestBatchItems(t *testing.T) {
+	ctx := context.Background()
+	var applied [][]domain.BatchOp
+	repo := &MockCartRepository{
+		findByUserIDFunc: func(ctx context.Context, userID string) (*domain.Cart, error) {
+			return &domain.Cart{ID: "7", UserID: userID, Currency: domain.DefaultCurrency, Items: []domain.CartItem{}}, nil
+		},
+		applyBatchFunc: func(ctx context.Context, userID string, ops []domain.BatchOp, atomic bool) ([]error, error) {
+			applied = append(applied, ops)
+			errs := make([]error, len(ops))
+			for i, op := range ops {
+				if op.Type == domain.BatchRemove && op.ItemID == "missing" {
+					errs[i] = domain.ErrNotFound
+					if atomic {
+						return errs[:i+1], nil
+					}
+				}
+			}
+			return errs, nil
+		},
+	}
+	service := NewCartService(repo, WithMaxBatchOperations(3))
+	add := domain.BatchOperation{Op: domain.BatchAdd, Item: &domain.AddToCartRequest{
+		ProductID: "1", ProductName: "Mug", ProductPrice: domain.NewMoney(999, domain.DefaultCurrency), Quantity: 1,
+	}}
+	remove := domain.BatchOperation{Op: domain.BatchRemove, ItemID: "missing"}
+	update := domain.BatchOperation{Op: domain.BatchUpdate, ItemID: "5", Quantity: 2}
+
+	statuses := func(batch *domain.BatchItemsResult) []domain.BatchOpStatus {
+		var got []domain.BatchOpStatus
+		for _, r := range batch.Results {
+			got = append(got, r.Status)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name        string
+		mode        domain.BatchMode
+		ops         []domain.BatchOperation
+		want        []domain.BatchOpStatus
+		wantApplied bool
+	}{
+		{"atomic success", domain.BatchAtomic, []domain.BatchOperation{add, update},
+			[]domain.BatchOpStatus{domain.BatchOpApplied, domain.BatchOpApplied}, true},
+		{"atomic repository failure", domain.BatchAtomic, []domain.BatchOperation{add, remove, update},
+			[]domain.BatchOpStatus{domain.BatchOpNotApplied, domain.BatchOpFailed, domain.BatchOpNotApplied}, false},
+		{"best effort repository failure", domain.BatchBestEffort, []domain.BatchOperation{add, remove, update},
+			[]domain.BatchOpStatus{domain.BatchOpApplied, domain.BatchOpFailed, domain.BatchOpApplied}, true},
+		{"best effort invalid op", domain.BatchBestEffort, []domain.BatchOperation{add, {Op: domain.BatchUpdate, ItemID: "5"}},
+			[]domain.BatchOpStatus{domain.BatchOpApplied, domain.BatchOpFailed}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch, err := service.BatchItems(ctx, "user-1", domain.BatchItemsRequest{Mode: tt.mode, Operations: tt.ops})
+			if err != nil {
+				t.Fatalf("BatchItems() error = %v", err)
+			}
+			if got := statuses(batch); !equalStatuses(got, tt.want) {
+				t.Errorf("statuses = %v, want %v", got, tt.want)
+			}
+			if batch.Applied != tt.wantApplied {
+				t.Errorf("Applied = %v, want %v", batch.Applied, tt.wantApplied)
+			}
+		})
+	}
+
+	// Validation failures of an atomic batch never reach the repository
+	applied = nil
+	batch, err := service.BatchItems(ctx, "user-1", domain.BatchItemsRequest{Operations: []domain.BatchOperation{
+		add, {Op: domain.BatchUpdate, ItemID: "5"},
+	}})
+	if err != nil {
+		t.Fatalf("BatchItems() error = %v", err)
+	}
+	if len(applied) != 0 || !errors.Is(batch.Results[1].Err, ErrInvalidQuantity) {
+		t.Errorf("atomic batch with an invalid op: applied %v, results %+v", applied, batch.Results)
+	}
+
+	_, err = service.BatchItems(ctx, "user-1", domain.BatchItemsRequest{Operations: []domain.BatchOperation{add, add, add, add}})
+	if !errors.Is(err, ErrBatchTooLarge) {
+		t.Errorf("BatchItems() of 4 ops error = %v, want ErrBatchTooLarge", err)
+	}
+}
+
+func equalStatuses(a, b []domain.BatchOpStatus) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
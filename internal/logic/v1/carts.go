@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/cart-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// A user may keep several named carts and wishlists. One of them is active:
+// the cart the /cart routes and every other CartService method act on, unless
+// the context selects another one with domain.WithCart.
+
+// ListCarts returns the user's carts and wishlists without their items
+func (s *CartService) ListCarts(ctx context.Context, userID string) ([]domain.CartSummary, error) {
+	ctx, span := middleware.StartSpan(ctx, "cart.list", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", userID),
+	))
+	defer span.End()
+
+	// Call repository
+	carts, err := s.cartRepo.ListCarts(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if carts == nil {
+		carts = []domain.CartSummary{}
+	}
+
+	span.SetAttributes(attribute.Int("carts.count", len(carts)))
+	return carts, nil
+}
+
+// CreateCart creates a named cart or wishlist. The new cart is not activated.
+// Guests only have their single active cart.
+func (s *CartService) CreateCart(ctx context.Context, userID string, req domain.CreateCartRequest) (*domain.CartSummary, error) {
+	ctx, span := middleware.StartSpan(ctx, "cart.create", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", userID),
+	))
+	defer span.End()
+
+	if domain.IsGuestOwner(userID) {
+		return nil, ErrUnauthorized
+	}
+	// Binding checks the raw name; a name of only spaces is blank once trimmed
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("blank cart name: %w", domain.ErrInvalidInput)
+	}
+	kind := req.Kind
+	if kind == "" {
+		kind = domain.KindCart
+	}
+
+	// Call repository
+	cart, err := s.cartRepo.CreateCart(ctx, userID, name, kind)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("cart.id", cart.ID), attribute.String("cart.kind", string(kind)))
+	span.AddEvent("cart.created")
+	return cart, nil
+}
+
+// DeleteCart deletes one of the user's carts with its items. Deleting the active
+// cart leaves the user without one until the next /cart mutation creates it.
+func (s *CartService) DeleteCart(ctx context.Context, userID, cartID string) error {
+	ctx, span := middleware.StartSpan(ctx, "cart.delete", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("cart.id", cartID),
+	))
+	defer span.End()
+
+	// Call repository
+	if err := s.cartRepo.DeleteCart(ctx, userID, cartID); err != nil {
+		span.RecordError(err)
+		return mutationError(err)
+	}
+
+	span.AddEvent("cart.deleted")
+	return nil
+}
+
+// ActivateCart makes cartID the user's active cart
+func (s *CartService) ActivateCart(ctx context.Context, userID, cartID string) error {
+	ctx, span := middleware.StartSpan(ctx, "cart.activate", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("cart.id", cartID),
+	))
+	defer span.End()
+
+	// Call repository
+	if err := s.cartRepo.SetActiveCart(ctx, userID, cartID); err != nil {
+		span.RecordError(err)
+		return mutationError(err)
+	}
+
+	span.AddEvent("cart.activated")
+	return nil
+}
@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+func TestCreateCart(t *testing.T) {
+	ctx := context.Background()
+	service := NewCartService(&MockCartRepository{})
+
+	cart, err := service.CreateCart(ctx, "user1", domain.CreateCartRequest{Name: " office supplies Q3 "})
+	if err != nil {
+		t.Fatalf("CreateCart() error = %v", err)
+	}
+	if cart.Name != "office supplies Q3" || cart.Kind != domain.KindCart {
+		t.Errorf("CreateCart() = %q %q, want trimmed name and default kind cart", cart.Name, cart.Kind)
+	}
+
+	_, err = service.CreateCart(ctx, domain.GuestOwnerID("abc"), domain.CreateCartRequest{Name: "gifts"})
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("guest CreateCart() error = %v, want ErrUnauthorized", err)
+	}
+
+	_, err = service.CreateCart(ctx, "user1", domain.CreateCartRequest{Name: "   "})
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("blank CreateCart() error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestDeleteCartNotOwned(t *testing.T) {
+	service := NewCartService(&MockCartRepository{
+		deleteCartFunc: func(ctx context.Context, userID, cartID string) error {
+			return domain.ErrCartNotFound
+		},
+	})
+
+	if err := service.DeleteCart(context.Background(), "user1", "7"); !errors.Is(err, ErrCartNotFound) {
+		t.Errorf("DeleteCart() error = %v, want ErrCartNotFound", err)
+	}
+}
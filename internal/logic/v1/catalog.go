@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+// WithProductCatalog makes the catalog authoritative for product details and
+// prices. Without it AddToCart trusts the name and price sent by the client and
+// GetCart serves the prices stored when items were added.
+func WithProductCatalog(catalog domain.ProductCatalog) CartServiceOption {
+	return func(s *CartService) {
+		s.catalog = catalog
+	}
+}
+
+// catalogItem builds the cart item for req from the catalog. The client's name,
+// price and currency are ignored; its tax class is kept only if the catalog has none.
+func (s *CartService) catalogItem(ctx context.Context, req domain.AddToCartRequest) (domain.CartItem, error) {
+	product, err := s.catalog.GetProduct(ctx, req.ProductID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return domain.CartItem{}, ErrProductNotFound
+	case errors.Is(err, domain.ErrCatalogUnavailable):
+		return domain.CartItem{}, fmt.Errorf("%w: %w", ErrCatalogUnavailable, err)
+	case err != nil:
+		return domain.CartItem{}, err
+	}
+	if !product.Available {
+		return domain.CartItem{}, ErrProductUnavailable
+	}
+
+	taxClass := product.TaxClass
+	if taxClass == "" {
+		taxClass = req.TaxClass
+	}
+	if taxClass == "" {
+		taxClass = DefaultTaxClass
+	}
+
+	return domain.CartItem{
+		ProductID:       req.ProductID,
+		ProductName:     product.Name,
+		ProductCategory: product.Category,
+		TaxClass:        strings.ToLower(taxClass),
+		ProductPrice:    product.Price,
+		Quantity:        req.Quantity,
+	}, nil
+}
+
+// refreshPrices replaces stored item prices with current catalog prices, keeping
+// the stored one as PreviousPrice when it differs, and flags items that can no
+// longer be ordered. If the catalog is unreachable the stored prices are left
+// in place and an error wrapping ErrCatalogUnavailable is returned.
+func (s *CartService) refreshPrices(ctx context.Context, cart *domain.Cart) error {
+	if s.catalog == nil || len(cart.Items) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(cart.Items))
+	for i, item := range cart.Items {
+		ids[i] = item.ProductID
+	}
+	products, err := s.catalog.GetProducts(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCatalogUnavailable, err)
+	}
+
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		product, ok := products[item.ProductID]
+		if !ok {
+			item.Unavailable = true
+			continue
+		}
+		item.Unavailable = !product.Available
+
+		// A cart holds a single currency; a product repriced in another one keeps its stored price
+		if cmp, err := product.Price.Cmp(item.ProductPrice); err != nil || cmp == 0 {
+			continue
+		}
+		previous := item.ProductPrice
+		item.PreviousPrice = &previous
+		item.ProductPrice = product.Price
+		item.PriceChanged = true
+	}
+	return nil
+}
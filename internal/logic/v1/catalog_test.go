@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/duynhne/cart-service/internal/core/catalog"
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+func TestAddToCartUsesCatalog(t *testing.T) {
+	ctx := context.Background()
+	products := catalog.NewMemoryCatalog(
+		domain.Product{ID: "1", Name: "Keyboard", Category: "electronics", Price: domain.NewMoney(4999, "USD"), Available: true},
+		domain.Product{ID: "2", Name: "Discontinued", Price: domain.NewMoney(100, "USD")},
+	)
+	var stored domain.CartItem
+	service := NewCartService(&MockCartRepository{
+		addItemFunc: func(ctx context.Context, userID string, item *domain.CartItem) error {
+			stored = *item
+			return nil
+		},
+	}, WithProductCatalog(products))
+
+	_, err := service.AddToCart(ctx, "user1", domain.AddToCartRequest{
+		ProductID:    "1",
+		ProductName:  "Free keyboard",
+		ProductPrice: domain.NewMoney(1, "USD"),
+		Quantity:     2,
+	})
+	if err != nil {
+		t.Fatalf("AddToCart() error = %v", err)
+	}
+	if stored.ProductName != "Keyboard" || stored.ProductCategory != "electronics" ||
+		stored.ProductPrice != domain.NewMoney(4999, "USD") || stored.TaxClass != DefaultTaxClass {
+		t.Errorf("stored item = %+v, want catalog name, category and price", stored)
+	}
+
+	tests := []struct {
+		productID string
+		wantErr   error
+	}{
+		{"2", ErrProductUnavailable},
+		{"3", ErrProductNotFound},
+	}
+	for _, tt := range tests {
+		_, err := service.AddToCart(ctx, "user1", domain.AddToCartRequest{ProductID: tt.productID, Quantity: 1})
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("AddToCart(%s) error = %v, want %v", tt.productID, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGetCartRefreshesPrices(t *testing.T) {
+	products := catalog.NewMemoryCatalog(
+		domain.Product{ID: "1", Name: "Keyboard", Price: domain.NewMoney(5499, "USD"), Available: true},
+		domain.Product{ID: "2", Name: "Mouse", Price: domain.NewMoney(1999, "USD"), Available: true},
+	)
+	service := NewCartService(&MockCartRepository{
+		findByUserIDFunc: func(ctx context.Context, userID string) (*domain.Cart, error) {
+			return &domain.Cart{
+				UserID:   userID,
+				Currency: "USD",
+				Items: []domain.CartItem{
+					{ID: "a", ProductID: "1", ProductPrice: domain.NewMoney(4999, "USD"), Quantity: 1},
+					{ID: "b", ProductID: "2", ProductPrice: domain.NewMoney(1999, "USD"), Quantity: 1},
+					{ID: "c", ProductID: "3", ProductPrice: domain.NewMoney(500, "USD"), Quantity: 1},
+				},
+			}, nil
+		},
+	}, WithProductCatalog(products))
+
+	cart, err := service.GetCart(context.Background(), "user1", domain.GetCartRequest{})
+	if err != nil {
+		t.Fatalf("GetCart() error = %v", err)
+	}
+
+	changed := cart.Items[0]
+	if !changed.PriceChanged || changed.PreviousPrice == nil ||
+		*changed.PreviousPrice != domain.NewMoney(4999, "USD") || changed.ProductPrice != domain.NewMoney(5499, "USD") {
+		t.Errorf("repriced item = %+v, want price 54.99 with previous_price 49.99", changed)
+	}
+	if same := cart.Items[1]; same.PriceChanged || same.PreviousPrice != nil || same.Unavailable {
+		t.Errorf("unchanged item = %+v, want no price change", same)
+	}
+	if !cart.Items[2].Unavailable {
+		t.Errorf("item missing from catalog = %+v, want unavailable", cart.Items[2])
+	}
+	if want := domain.NewMoney(5499+1999+500, "USD"); cart.Subtotal != want {
+		t.Errorf("Subtotal = %v, want %v (current prices)", cart.Subtotal, want)
+	}
+}
@@ -0,0 +1,164 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/cart-service/middleware"
+	"github.com/duynhne/pkg/logger/clog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Checkout freezes the priced cart into a snapshot and locks the cart until the
+// order service confirms or cancels the snapshot. Every mutation of a locked
+// cart fails with domain.ErrCartLocked. If the order service never answers the
+// lock lapses after the lock TTL and the cart can be changed again.
+
+// WithCheckout enables Checkout, locking carts for lockTTL at a time
+func WithCheckout(repo domain.CheckoutRepository, lockTTL time.Duration) CartServiceOption {
+	return func(s *CartService) {
+		s.checkout = repo
+		s.lockTTL = lockTTL
+	}
+}
+
+// errCheckoutDisabled is returned when the service was built without WithCheckout
+var errCheckoutDisabled = errors.New("checkout not configured")
+
+// Checkout validates and prices the user's cart, then freezes it into a pending
+// snapshot and locks the cart. The If-Match precondition in ctx, if any, is
+// checked against the cart that was priced. Unlike GetCart it never falls back
+// to stored prices: an unreachable catalog fails it with ErrCatalogUnavailable.
+func (s *CartService) Checkout(ctx context.Context, userID string, req domain.CheckoutRequest) (*domain.CartSnapshot, error) {
+	ctx, span := middleware.StartSpan(ctx, "cart.checkout", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", userID),
+	))
+	defer span.End()
+
+	if s.checkout == nil {
+		return nil, errCheckoutDisabled
+	}
+
+	cart, err := s.loadPricedCart(ctx, userID, domain.GetCartRequest{Region: req.Region, Country: req.Country, State: req.State}, true)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, fmt.Errorf("checkout cart %q: %w", cart.ID, ErrCartEmpty)
+	}
+	for _, item := range cart.Items {
+		if item.Unavailable {
+			return nil, fmt.Errorf("checkout cart %q: product %s: %w", cart.ID, item.ProductID, ErrProductUnavailable)
+		}
+	}
+	if !domain.VersionMatches(ctx, cart.Version) {
+		return nil, ErrCartModified
+	}
+
+	snapshot := domain.NewCartSnapshot(cart, time.Now().Add(s.lockTTL))
+
+	// Call repository
+	if err := s.checkout.Lock(ctx, userID, snapshot); err != nil {
+		span.RecordError(err)
+		return nil, mutationError(err)
+	}
+	// Keep the stock reserved for as long as the order may take
+	if s.inventory != nil && s.reservations != nil {
+		if err := s.reservations.Refresh(ctx, userID, snapshot.ExpiresAt); err != nil {
+			clog.WarnContext(ctx, "Failed to extend inventory reservations for checkout", "error", err)
+		}
+	}
+
+	span.SetAttributes(
+		attribute.String("snapshot.id", snapshot.ID),
+		attribute.Int("items.count", len(snapshot.Items)),
+	)
+	span.AddEvent("cart.locked")
+	return snapshot, nil
+}
+
+// GetSnapshot returns a checkout snapshot
+func (s *CartService) GetSnapshot(ctx context.Context, id string) (*domain.CartSnapshot, error) {
+	ctx, span := middleware.StartSpan(ctx, "cart.checkout.get", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("snapshot.id", id),
+	))
+	defer span.End()
+
+	if s.checkout == nil {
+		return nil, errCheckoutDisabled
+	}
+
+	// Call repository
+	snapshot, err := s.checkout.Get(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, checkoutError(fmt.Sprintf("get checkout snapshot %s", id), err)
+	}
+	return snapshot, nil
+}
+
+// ConfirmCheckout records the order placed from a snapshot, which redeems its
+// coupons and empties and unlocks the cart. Confirming again with the same
+// order ID succeeds.
+func (s *CartService) ConfirmCheckout(ctx context.Context, id, orderID string) error {
+	ctx, span := middleware.StartSpan(ctx, "cart.checkout.confirm", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("snapshot.id", id),
+		attribute.String("order.id", orderID),
+	))
+	defer span.End()
+
+	if s.checkout == nil {
+		return errCheckoutDisabled
+	}
+
+	// Call repository
+	if err := s.checkout.Confirm(ctx, id, orderID); err != nil {
+		span.RecordError(err)
+		return checkoutError(fmt.Sprintf("confirm checkout snapshot %s", id), err)
+	}
+
+	span.AddEvent("cart.checked_out")
+	return nil
+}
+
+// CancelCheckout abandons a snapshot and unlocks the cart unchanged.
+// Cancelling again succeeds.
+func (s *CartService) CancelCheckout(ctx context.Context, id string) error {
+	ctx, span := middleware.StartSpan(ctx, "cart.checkout.cancel", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("snapshot.id", id),
+	))
+	defer span.End()
+
+	if s.checkout == nil {
+		return errCheckoutDisabled
+	}
+
+	// Call repository
+	if err := s.checkout.Cancel(ctx, id); err != nil {
+		span.RecordError(err)
+		return checkoutError(fmt.Sprintf("cancel checkout snapshot %s", id), err)
+	}
+
+	span.AddEvent("cart.unlocked")
+	return nil
+}
+
+// checkoutError maps repository snapshot errors to checkout sentinels
+func checkoutError(op string, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return fmt.Errorf("%s: %w", op, ErrSnapshotNotFound)
+	case errors.Is(err, domain.ErrConflict):
+		return fmt.Errorf("%s: %w", op, ErrCheckoutClosed)
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
@@ -0,0 +1,156 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+// mockCheckouts is a domain.CheckoutRepository that records the locked snapshot
+type mockCheckouts struct {
+	locked  *domain.CartSnapshot
+	lockErr error
+}
+
+func (m *mockCheckouts) Lock(ctx context.Context, userID string, snapshot *domain.CartSnapshot) error {
+	if m.lockErr != nil {
+		return m.lockErr
+	}
+	snapshot.ID = "1"
+	m.locked = snapshot
+	return nil
+}
+func (m *mockCheckouts) Get(ctx context.Context, id string) (*domain.CartSnapshot, error) {
+	if m.locked == nil || m.locked.ID != id {
+		return nil, domain.ErrNotFound
+	}
+	return m.locked, nil
+}
+func (m *mockCheckouts) Confirm(ctx context.Context, id, orderID string) error {
+	if m.locked == nil || m.locked.ID != id {
+		return domain.ErrNotFound
+	}
+	if m.locked.OrderID != "" && m.locked.OrderID != orderID {
+		return domain.ErrConflict
+	}
+	m.locked.OrderID = orderID
+	return nil
+}
+func (m *mockCheckouts) Cancel(ctx context.Context, id string) error {
+	return domain.ErrNotFound
+}
+
+func TestCheckout(t *testing.T) {
+	ctx := context.Background()
+	checkouts := &mockCheckouts{}
+	repo := &MockCartRepository{
+		findByUserIDFunc: func(ctx context.Context, userID string) (*domain.Cart, error) {
+			return &domain.Cart{
+				ID:       "7",
+				UserID:   userID,
+				Version:  4,
+				Currency: domain.DefaultCurrency,
+				Items: []domain.CartItem{
+					{ID: "a", ProductID: "1", ProductPrice: domain.NewMoney(1000, domain.DefaultCurrency), Quantity: 2},
+				},
+			}, nil
+		},
+	}
+	service := NewCartService(repo, WithCheckout(checkouts, 30*time.Minute))
+
+	snapshot, err := service.Checkout(ctx, "user-1", domain.CheckoutRequest{})
+	if err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+	if snapshot.ID != "1" || snapshot.CartVersion != 4 || snapshot.Status != domain.SnapshotPending {
+		t.Errorf("snapshot = %+v, want pending snapshot 1 of cart version 4", snapshot)
+	}
+	if snapshot.Subtotal.Amount != 2000 || !snapshot.Total.IsPositive() {
+		t.Errorf("snapshot totals = %v / %v, want the priced cart", snapshot.Subtotal, snapshot.Total)
+	}
+	if until := time.Until(snapshot.ExpiresAt); until < 29*time.Minute || until > 30*time.Minute {
+		t.Errorf("snapshot expires in %v, want the 30m lock TTL", until)
+	}
+
+	// The If-Match precondition is checked against the priced cart
+	_, err = service.Checkout(domain.WithExpectedVersions(ctx, 3), "user-1", domain.CheckoutRequest{})
+	if !errors.Is(err, ErrCartModified) {
+		t.Errorf("Checkout() of a stale version error = %v, want ErrCartModified", err)
+	}
+
+	checkouts.lockErr = domain.ErrCartLocked
+	if _, err := service.Checkout(ctx, "user-1", domain.CheckoutRequest{}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Checkout() of a locked cart error = %v, want ErrConflict", err)
+	}
+
+	if err := service.ConfirmCheckout(ctx, "1", "order-1"); err != nil {
+		t.Errorf("ConfirmCheckout() error = %v", err)
+	}
+	if err := service.ConfirmCheckout(ctx, "1", "order-2"); !errors.Is(err, ErrCheckoutClosed) {
+		t.Errorf("ConfirmCheckout() with another order error = %v, want ErrCheckoutClosed", err)
+	}
+	if err := service.CancelCheckout(ctx, "2"); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("CancelCheckout() of a missing snapshot error = %v, want ErrSnapshotNotFound", err)
+	}
+}
+
+// downCatalog is a domain.ProductCatalog that cannot be reached
+type downCatalog struct{}
+
+func (downCatalog) GetProduct(ctx context.Context, productID string) (*domain.Product, error) {
+	return nil, domain.ErrCatalogUnavailable
+}
+func (downCatalog) GetProducts(ctx context.Context, productIDs []string) (map[string]domain.Product, error) {
+	return nil, domain.ErrCatalogUnavailable
+}
+
+func TestCheckoutCatalogUnavailable(t *testing.T) {
+	checkouts := &mockCheckouts{}
+	repo := &MockCartRepository{
+		findByUserIDFunc: func(ctx context.Context, userID string) (*domain.Cart, error) {
+			return &domain.Cart{
+				ID:       "7",
+				UserID:   userID,
+				Currency: domain.DefaultCurrency,
+				Items: []domain.CartItem{
+					{ID: "a", ProductID: "1", ProductPrice: domain.NewMoney(1000, domain.DefaultCurrency), Quantity: 1},
+				},
+			}, nil
+		},
+	}
+	service := NewCartService(repo, WithProductCatalog(downCatalog{}), WithCheckout(checkouts, 30*time.Minute))
+
+	// The cart itself is still served at its stored prices
+	if _, err := service.GetCart(context.Background(), "user-1", domain.GetCartRequest{}); err != nil {
+		t.Fatalf("GetCart() error = %v", err)
+	}
+
+	_, err := service.Checkout(context.Background(), "user-1", domain.CheckoutRequest{})
+	if !errors.Is(err, ErrCatalogUnavailable) {
+		t.Errorf("Checkout() error = %v, want ErrCatalogUnavailable", err)
+	}
+	if checkouts.locked != nil {
+		t.Error("Checkout() locked the cart at unverified prices")
+	}
+}
+
+func TestCheckoutEmptyCart(t *testing.T) {
+	checkouts := &mockCheckouts{}
+	repo := &MockCartRepository{
+		findByUserIDFunc: func(ctx context.Context, userID string) (*domain.Cart, error) {
+			return &domain.Cart{ID: "7", UserID: userID, Currency: domain.DefaultCurrency, Items: []domain.CartItem{}}, nil
+		},
+	}
+	service := NewCartService(repo, WithCheckout(checkouts, 30*time.Minute))
+
+	_, err := service.Checkout(context.Background(), "user-1", domain.CheckoutRequest{})
+	if !errors.Is(err, ErrCartEmpty) {
+		t.Errorf("Checkout() error = %v, want ErrCartEmpty", err)
+	}
+	if checkouts.locked != nil {
+		t.Error("Checkout() of an empty cart locked it")
+	}
+}
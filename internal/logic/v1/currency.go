@@ -0,0 +1,185 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+// ExchangeRate converts amounts from one currency to another: to = from * Rate.
+type ExchangeRate struct {
+	From string
+	To   string
+	Rate *big.Rat
+	AsOf time.Time
+}
+
+// RateProvider supplies exchange rates for converting cart totals.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (ExchangeRate, error)
+}
+
+// FileRateProvider serves rates from a static JSON document relative to a base currency.
+// It is meant for tests and local development; production deployments should plug in
+// a provider backed by a rates service.
+//
+// File layout:
+//
+//	{"base": "USD", "as_of": "2026-10-01T00:00:00Z", "rates": {"EUR": "0.92", "JPY": "149.50"}}
+type FileRateProvider struct {
+	base  string
+	asOf  time.Time
+	rates map[string]*big.Rat
+}
+
+// rateFile is the on-disk representation read by FileRateProvider.
+type rateFile struct {
+	Base  string                 `json:"base"`
+	AsOf  time.Time              `json:"as_of"`
+	Rates map[string]json.Number `json:"rates"`
+}
+
+// NewFileRateProvider loads rates from a JSON file.
+func NewFileRateProvider(path string) (*FileRateProvider, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from operator config
+	if err != nil {
+		return nil, fmt.Errorf("read exchange rates: %w", err)
+	}
+	return ParseRates(data)
+}
+
+// ParseRates builds a FileRateProvider from the JSON document.
+func ParseRates(data []byte) (*FileRateProvider, error) {
+	var doc rateFile
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse exchange rates: %w", err)
+	}
+	if doc.Base == "" {
+		return nil, fmt.Errorf("parse exchange rates: base currency is required")
+	}
+
+	p := &FileRateProvider{
+		base:  strings.ToUpper(doc.Base),
+		asOf:  doc.AsOf,
+		rates: make(map[string]*big.Rat, len(doc.Rates)),
+	}
+	for currency, raw := range doc.Rates {
+		rate, ok := new(big.Rat).SetString(raw.String())
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("parse exchange rates: invalid rate %q for %s", raw, currency)
+		}
+		p.rates[strings.ToUpper(currency)] = rate
+	}
+	p.rates[p.base] = big.NewRat(1, 1)
+	return p, nil
+}
+
+// Rate implements RateProvider. Cross rates are derived through the base currency.
+func (p *FileRateProvider) Rate(_ context.Context, from, to string) (ExchangeRate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return ExchangeRate{}, fmt.Errorf("rate %s->%s: %w", from, to, ErrUnsupportedCurrency)
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return ExchangeRate{}, fmt.Errorf("rate %s->%s: %w", from, to, ErrUnsupportedCurrency)
+	}
+
+	return ExchangeRate{
+		From: from,
+		To:   to,
+		Rate: new(big.Rat).Quo(toRate, fromRate),
+		AsOf: p.asOf,
+	}, nil
+}
+
+// convert converts amount into the target currency, rounding half-even.
+func (s *CartService) convert(ctx context.Context, amount domain.Money, to string) (domain.Money, ExchangeRate, error) {
+	if amount.Currency == to {
+		return amount, ExchangeRate{From: to, To: to, Rate: big.NewRat(1, 1)}, nil
+	}
+	if s.rates == nil {
+		return domain.Money{}, ExchangeRate{}, fmt.Errorf("convert %s->%s: no rate provider: %w",
+			amount.Currency, to, ErrUnsupportedCurrency)
+	}
+
+	rate, err := s.rates.Rate(ctx, amount.Currency, to)
+	if err != nil {
+		return domain.Money{}, ExchangeRate{}, err
+	}
+
+	// Rescale minor units between currencies with different exponents.
+	factor := new(big.Rat).Mul(rate.Rate, exponentShift(amount.Currency, to))
+	converted, err := amount.MulRat(factor, domain.RoundHalfEven)
+	if err != nil {
+		return domain.Money{}, ExchangeRate{}, err
+	}
+	converted.Currency = to
+	return converted, rate, nil
+}
+
+// exponentShift returns 10^(exp(to) - exp(from)) to rescale minor units.
+func exponentShift(from, to string) *big.Rat {
+	diff := domain.CurrencyExponent(to) - domain.CurrencyExponent(from)
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(diff))), nil)
+	if diff < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), pow)
+	}
+	return new(big.Rat).SetInt(pow)
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// applyConversion fills cart.Conversion with totals in the requested display currency.
+// Each amount is converted separately and the total is recomputed from them.
+func (s *CartService) applyConversion(ctx context.Context, cart *domain.Cart, currency string) error {
+	if currency == "" || currency == cart.Currency {
+		return nil
+	}
+
+	converted := domain.Cart{Currency: currency, TaxIncluded: cart.TaxIncluded}
+	var rate ExchangeRate
+	for _, f := range []struct {
+		from domain.Money
+		to   *domain.Money
+	}{
+		{cart.Subtotal, &converted.Subtotal},
+		{cart.Discount, &converted.Discount},
+		{cart.Shipping, &converted.Shipping},
+		{cart.Tax, &converted.Tax},
+	} {
+		var err error
+		if *f.to, rate, err = s.convert(ctx, f.from, currency); err != nil {
+			return err
+		}
+	}
+	total, err := totalOf(&converted)
+	if err != nil {
+		return err
+	}
+
+	cart.Conversion = &domain.CurrencyConversion{
+		Currency: currency,
+		Rate:     strings.TrimRight(strings.TrimRight(rate.Rate.FloatString(8), "0"), "."),
+		RateAsOf: rate.AsOf,
+		Subtotal: converted.Subtotal,
+		Discount: converted.Discount,
+		Shipping: converted.Shipping,
+		Tax:      converted.Tax,
+		Total:    total,
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+const testRates = `{"base": "USD", "as_of": "2026-10-01T00:00:00Z", "rates": {"EUR": "0.92", "JPY": "149.50"}}`
+
+func TestFileRateProvider(t *testing.T) {
+	rates, err := ParseRates([]byte(testRates))
+	if err != nil {
+		t.Fatalf("ParseRates() error = %v", err)
+	}
+
+	rate, err := rates.Rate(context.Background(), "eur", "JPY")
+	if err != nil {
+		t.Fatalf("Rate() error = %v", err)
+	}
+	if got := rate.Rate.FloatString(4); got != "162.5000" {
+		t.Errorf("EUR->JPY = %s, want 162.5000", got)
+	}
+
+	if _, err := rates.Rate(context.Background(), "USD", "GBP"); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("USD->GBP error = %v, want ErrUnsupportedCurrency", err)
+	}
+
+	if _, err := ParseRates([]byte(`{"base": "USD", "rates": {"EUR": "-1"}}`)); err == nil {
+		t.Error("expected error for negative rate")
+	}
+}
+
+func TestGetCartConvertsTotals(t *testing.T) {
+	rates, err := ParseRates([]byte(testRates))
+	if err != nil {
+		t.Fatalf("ParseRates() error = %v", err)
+	}
+	mockRepo := &MockCartRepository{
+		findByUserIDFunc: func(ctx context.Context, userID string) (*domain.Cart, error) {
+			return &domain.Cart{UserID: userID, Currency: "USD", Items: []domain.CartItem{
+				{ProductID: "p1", ProductPrice: usd(1000), Quantity: 2},
+			}}, nil
+		},
+	}
+	service := NewCartService(mockRepo, WithRateProvider(rates))
+
+	cart, err := service.GetCart(context.Background(), "user1", domain.GetCartRequest{Currency: "JPY"})
+	if err != nil {
+		t.Fatalf("GetCart() error = %v", err)
+	}
+	if cart.Conversion == nil {
+		t.Fatal("expected conversion")
+	}
+	// 25.00 USD * 149.50 = 3737.5 JPY, rounded half-even
+	if want := domain.NewMoney(3738, "JPY"); cart.Conversion.Total != want {
+		t.Errorf("Conversion.Total = %v, want %v", cart.Conversion.Total, want)
+	}
+	if cart.Conversion.Rate != "149.5" {
+		t.Errorf("Conversion.Rate = %s, want 149.5", cart.Conversion.Rate)
+	}
+
+	if _, err := service.GetCart(context.Background(), "user1", domain.GetCartRequest{Currency: "GBP"}); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("GBP error = %v, want ErrUnsupportedCurrency", err)
+	}
+}
+
+func TestAddToCartMixedCurrency(t *testing.T) {
+	mockRepo := &MockCartRepository{
+		addItemFunc: func(ctx context.Context, userID string, item *domain.CartItem) error {
+			if item.ProductPrice.Currency != "EUR" {
+				t.Errorf("item currency = %s, want EUR", item.ProductPrice.Currency)
+			}
+			return domain.ErrCurrencyMismatch
+		},
+	}
+	service := NewCartService(mockRepo)
+
+	_, err := service.AddToCart(context.Background(), "user1", domain.AddToCartRequest{
+		ProductID:    "p1",
+		ProductName:  "Product",
+		ProductPrice: domain.NewMoney(999, "EUR"),
+		Quantity:     1,
+		Currency:     "EUR",
+	})
+	if !errors.Is(err, ErrMixedCurrency) {
+		t.Errorf("AddToCart() error = %v, want ErrMixedCurrency", err)
+	}
+}
@@ -35,14 +35,87 @@ var (
 	// HTTP Status: 400 Bad Request
 	ErrInvalidQuantity = errors.New("invalid quantity")
 
+	// ErrInvalidPrice indicates the provided product price is invalid (zero or negative).
+	// HTTP Status: 400 Bad Request
+	ErrInvalidPrice = errors.New("invalid price")
+
+	// ErrInvalidProductName indicates a product name is missing where the client must provide it.
+	// HTTP Status: 400 Bad Request
+	ErrInvalidProductName = errors.New("invalid product name")
+
+	// ErrProductNotFound indicates the product catalog does not know the product.
+	// HTTP Status: 404 Not Found
+	ErrProductNotFound = errors.New("product not found")
+
+	// ErrProductUnavailable indicates the product exists but cannot currently be ordered.
+	// HTTP Status: 409 Conflict
+	ErrProductUnavailable = errors.New("product unavailable")
+
+	// ErrCatalogUnavailable indicates the product catalog could not be reached.
+	// HTTP Status: 503 Service Unavailable
+	ErrCatalogUnavailable = errors.New("product catalog unavailable")
+
+	// ErrMixedCurrency indicates an item's currency differs from the currency of the cart.
+	// HTTP Status: 409 Conflict
+	ErrMixedCurrency = errors.New("item currency does not match cart currency")
+
+	// ErrUnsupportedCurrency indicates no exchange rate is available for the requested currency.
+	// HTTP Status: 400 Bad Request
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+	// ErrCouponNotFound indicates the coupon code does not exist or is not attached to the cart.
+	// HTTP Status: 404 Not Found
+	ErrCouponNotFound = errors.New("coupon not found")
+
+	// ErrCouponNotApplicable indicates the cart does not meet the coupon's conditions
+	// (inactive or expired, minimum subtotal, no eligible items).
+	// HTTP Status: 422 Unprocessable Entity
+	ErrCouponNotApplicable = errors.New("coupon not applicable to cart")
+
+	// ErrCouponUsageLimitReached indicates every use of the coupon is taken by other carts or orders.
+	// HTTP Status: 409 Conflict
+	ErrCouponUsageLimitReached = errors.New("coupon usage limit reached")
+
+	// ErrNoGuestCart indicates a merge was requested without a valid guest cart token.
+	// HTTP Status: 400 Bad Request
+	ErrNoGuestCart = errors.New("no guest cart to merge")
+
+	// ErrCartModified indicates the cart changed since the version in the If-Match precondition.
+	// HTTP Status: 412 Precondition Failed
+	ErrCartModified = errors.New("cart was modified")
+
 	// ErrCartItemNotFound indicates the specified cart item does not exist.
 	// HTTP Status: 404 Not Found
 	ErrCartItemNotFound = errors.New("cart item not found")
 
+	// ErrSavedItemNotFound indicates the specified item is not in the saved-for-later list.
+	// HTTP Status: 404 Not Found
+	ErrSavedItemNotFound = errors.New("saved item not found")
+
 	// ErrInsufficientStock indicates there is not enough stock for the requested quantity.
 	// HTTP Status: 400 Bad Request
 	ErrInsufficientStock = errors.New("insufficient stock")
 
+	// ErrWebhookNotFound indicates the webhook subscription or delivery does not exist.
+	// HTTP Status: 404 Not Found
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrInvalidEventType indicates a webhook subscription names an unknown event type.
+	// HTTP Status: 400 Bad Request
+	ErrInvalidEventType = errors.New("invalid event type")
+
+	// ErrBatchTooLarge indicates a batch has more operations than allowed.
+	// HTTP Status: 400 Bad Request
+	ErrBatchTooLarge = errors.New("too many batch operations")
+
+	// ErrSnapshotNotFound indicates the checkout snapshot does not exist.
+	// HTTP Status: 404 Not Found
+	ErrSnapshotNotFound = errors.New("checkout snapshot not found")
+
+	// ErrCheckoutClosed indicates the checkout snapshot was already resolved or its lock lapsed.
+	// HTTP Status: 409 Conflict
+	ErrCheckoutClosed = errors.New("checkout no longer pending")
+
 	// ErrUnauthorized indicates the user is not authorized to access the cart.
 	// HTTP Status: 403 Forbidden
 	ErrUnauthorized = errors.New("unauthorized access")
@@ -0,0 +1,139 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/pkg/logger/clog"
+)
+
+// Stock checks are advisory: when the inventory service cannot be reached the
+// change goes through and the cart is served without stock statuses. With
+// reservations enabled, the stock a cart can use is the stock on hand minus the
+// unexpired reservations of every other cart.
+
+// WithInventory enables stock checks on AddToCart and UpdateItemQuantity and
+// per-item stock statuses on GetCart
+func WithInventory(inventory domain.InventoryClient) CartServiceOption {
+	return func(s *CartService) {
+		s.inventory = inventory
+	}
+}
+
+// WithReservations makes every cart change reserve the cart's quantities for
+// ttl. Only effective together with WithInventory.
+func WithReservations(repo domain.ReservationRepository, ttl time.Duration) CartServiceOption {
+	return func(s *CartService) {
+		s.reservations = repo
+		s.reservationTTL = ttl
+	}
+}
+
+// availableStock returns the stock of productIDs that the user's cart can use
+func (s *CartService) availableStock(ctx context.Context, userID string, productIDs []string) (map[string]int, error) {
+	stock, err := s.inventory.Stock(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	if s.reservations == nil {
+		return stock, nil
+	}
+
+	held, err := s.reservations.HeldByOthers(ctx, userID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	for id, quantity := range held {
+		stock[id] -= quantity
+	}
+	return stock, nil
+}
+
+// checkStock returns ErrInsufficientStock if the cart cannot hold quantity of productID
+func (s *CartService) checkStock(ctx context.Context, userID, productID string, quantity int) error {
+	available, err := s.availableStock(ctx, userID, []string{productID})
+	if err != nil {
+		clog.WarnContext(ctx, "Inventory unavailable, skipping stock check", "product_id", productID, "error", err)
+		return nil
+	}
+	if quantity > available[productID] {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+// checkAddStock checks the stock for quantity more of productID than the cart holds
+func (s *CartService) checkAddStock(ctx context.Context, userID, productID string, quantity int) error {
+	cart, err := s.cartRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return mutationError(err)
+	}
+	for _, item := range cart.Items {
+		if item.ProductID == productID {
+			quantity += item.Quantity
+		}
+	}
+	return s.checkStock(ctx, userID, productID, quantity)
+}
+
+// checkUpdateStock checks the stock for quantity of the product in cart item itemID
+func (s *CartService) checkUpdateStock(ctx context.Context, userID, itemID string, quantity int) error {
+	cart, err := s.cartRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return mutationError(err)
+	}
+	for _, item := range cart.Items {
+		if item.ID == itemID {
+			return s.checkStock(ctx, userID, item.ProductID, quantity)
+		}
+	}
+	return ErrCartItemNotFound
+}
+
+// checkMoveStock checks the stock for moving saved item itemID into the cart
+func (s *CartService) checkMoveStock(ctx context.Context, userID, itemID string) error {
+	saved, err := s.cartRepo.ListSaved(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, item := range saved {
+		if item.ID == itemID {
+			return s.checkAddStock(ctx, userID, item.ProductID, item.Quantity)
+		}
+	}
+	return ErrSavedItemNotFound
+}
+
+// holdStock renews the reservations of the user's cart after a change. A
+// failure only shortens the hold, so it is logged rather than returned.
+func (s *CartService) holdStock(ctx context.Context, userID string) {
+	if s.inventory == nil || s.reservations == nil {
+		return
+	}
+	if err := s.reservations.Refresh(ctx, userID, time.Now().Add(s.reservationTTL)); err != nil {
+		clog.WarnContext(ctx, "Failed to refresh inventory reservations", "error", err)
+	}
+}
+
+// annotateStock sets the stock status of every cart item
+func (s *CartService) annotateStock(ctx context.Context, userID string, cart *domain.Cart) {
+	if s.inventory == nil || len(cart.Items) == 0 {
+		return
+	}
+
+	ids := make([]string, len(cart.Items))
+	for i, item := range cart.Items {
+		ids[i] = item.ProductID
+	}
+	available, err := s.availableStock(ctx, userID, ids)
+	if err != nil {
+		clog.WarnContext(ctx, "Inventory unavailable, serving cart without stock status", "error", err)
+		return
+	}
+
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		item.StockStatus = domain.StockStatusFor(item.Quantity, available[item.ProductID])
+	}
+}
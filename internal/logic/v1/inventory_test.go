@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/cart-service/internal/core/inventory"
+)
+
+// mockReservations is a domain.ReservationRepository with fixed holds by other carts
+type mockReservations struct {
+	held      map[string]int
+	refreshed int
+}
+
+func (m *mockReservations) Refresh(ctx context.Context, userID string, expiresAt time.Time) error {
+	m.refreshed++
+	return nil
+}
+func (m *mockReservations) HeldByOthers(ctx context.Context, userID string, productIDs []string) (map[string]int, error) {
+	return m.held, nil
+}
+func (m *mockReservations) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// failingInventory is a domain.InventoryClient whose service is down
+type failingInventory struct{}
+
+func (failingInventory) Stock(ctx context.Context, productIDs []string) (map[string]int, error) {
+	return nil, domain.ErrInventoryUnavailable
+}
+
+func stockTestRepo() *MockCartRepository {
+	return &MockCartRepository{
+		findByUserIDFunc: func(ctx context.Context, userID string) (*domain.Cart, error) {
+			return &domain.Cart{
+				UserID:   userID,
+				Currency: domain.DefaultCurrency,
+				Items: []domain.CartItem{
+					{ID: "a", ProductID: "1", ProductPrice: domain.NewMoney(1000, domain.DefaultCurrency), Quantity: 2},
+					{ID: "b", ProductID: "2", ProductPrice: domain.NewMoney(1000, domain.DefaultCurrency), Quantity: 2},
+					{ID: "c", ProductID: "3", ProductPrice: domain.NewMoney(1000, domain.DefaultCurrency), Quantity: 1},
+				},
+			}, nil
+		},
+	}
+}
+
+func TestStockChecks(t *testing.T) {
+	ctx := context.Background()
+	reservations := &mockReservations{held: map[string]int{"1": 2}}
+	service := NewCartService(stockTestRepo(),
+		WithInventory(inventory.NewMemoryInventory(map[string]int{"1": 5, "2": 10})),
+		WithReservations(reservations, 15*time.Minute),
+	)
+	add := func(productID string, quantity int) error {
+		_, err := service.AddToCart(ctx, "user1", domain.AddToCartRequest{
+			ProductID: productID, ProductName: "P", ProductPrice: domain.NewMoney(1000, domain.DefaultCurrency), Quantity: quantity,
+		})
+		return err
+	}
+
+	// Product 1: 5 on hand, 2 held by other carts, 2 already in this cart
+	if err := add("1", 2); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("AddToCart(1, 2) error = %v, want ErrInsufficientStock", err)
+	}
+	if err := add("1", 1); err != nil {
+		t.Errorf("AddToCart(1, 1) error = %v", err)
+	}
+	if reservations.refreshed != 1 {
+		t.Errorf("reservations refreshed %d times, want 1", reservations.refreshed)
+	}
+	if err := add("4", 1); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("AddToCart(untracked) error = %v, want ErrInsufficientStock", err)
+	}
+
+	if err := service.UpdateItemQuantity(ctx, "user1", "a", 4); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("UpdateItemQuantity(a, 4) error = %v, want ErrInsufficientStock", err)
+	}
+	if err := service.UpdateItemQuantity(ctx, "user1", "b", 10); err != nil {
+		t.Errorf("UpdateItemQuantity(b, 10) error = %v", err)
+	}
+	if err := service.UpdateItemQuantity(ctx, "user1", "z", 1); !errors.Is(err, ErrCartItemNotFound) {
+		t.Errorf("UpdateItemQuantity(z) error = %v, want ErrCartItemNotFound", err)
+	}
+
+	cart, err := service.GetCart(ctx, "user1", domain.GetCartRequest{})
+	if err != nil {
+		t.Fatalf("GetCart() error = %v", err)
+	}
+	want := []domain.StockStatus{domain.StockInStock, domain.StockInStock, domain.StockOutOfStock}
+	for i, item := range cart.Items {
+		if item.StockStatus != want[i] {
+			t.Errorf("item %s stock_status = %q, want %q", item.ID, item.StockStatus, want[i])
+		}
+	}
+}
+
+func TestStockChecksInventoryDown(t *testing.T) {
+	ctx := context.Background()
+	service := NewCartService(stockTestRepo(), WithInventory(failingInventory{}))
+
+	if err := service.UpdateItemQuantity(ctx, "user1", "a", 100); err != nil {
+		t.Errorf("UpdateItemQuantity() error = %v, want the change to go through", err)
+	}
+	cart, err := service.GetCart(ctx, "user1", domain.GetCartRequest{})
+	if err != nil {
+		t.Fatalf("GetCart() error = %v", err)
+	}
+	if cart.Items[0].StockStatus != "" {
+		t.Errorf("stock_status = %q, want none while the inventory is down", cart.Items[0].StockStatus)
+	}
+}
@@ -0,0 +1,152 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/cart-service/middleware"
+	"github.com/duynhne/pkg/logger/clog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	relayBatchSize = 100
+	// relayLease is how long claimed events stay reserved for one relay; a
+	// batch stops publishing halfway through it so its outcome is recorded in time
+	relayLease = 2 * time.Minute
+	// Failed deliveries back off exponentially from relayMinBackoff up to relayMaxBackoff
+	relayMinBackoff = time.Second
+	relayMaxBackoff = 10 * time.Minute
+	// publishedRetention is how long delivered events stay in the outbox
+	publishedRetention = 7 * 24 * time.Hour
+)
+
+var outboxDeliveries = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "outbox_deliveries_total",
+		Help: "Cart event delivery attempts by the outbox relay",
+	},
+	[]string{"result"}, // published, failed
+)
+
+// OutboxRelay publishes the events cart mutations write to the outbox. Delivery
+// is at least once: an event is retried with backoff until the publisher
+// accepts it, and redelivered if a relay stops before recording the outcome.
+// Events are published in outbox order; a failure holds back the rest of its
+// batch. Several replicas may relay at once, each claiming different events.
+type OutboxRelay struct {
+	repo      domain.OutboxRepository
+	publisher domain.EventPublisher
+	interval  time.Duration
+}
+
+// NewOutboxRelay creates a relay that polls the outbox every interval
+func NewOutboxRelay(repo domain.OutboxRepository, publisher domain.EventPublisher, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{repo: repo, publisher: publisher, interval: interval}
+}
+
+// Run relays events every interval, and purges delivered events hourly, until ctx is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	purge := time.NewTicker(time.Hour)
+	defer purge.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Relay(ctx); err != nil && ctx.Err() == nil {
+				clog.WarnContext(ctx, "Outbox relay failed", "error", err)
+			}
+		case <-purge.C:
+			deleted, err := r.repo.DeletePublished(ctx, time.Now().Add(-publishedRetention))
+			if err != nil {
+				clog.WarnContext(ctx, "Failed to purge published outbox events", "error", err)
+				continue
+			}
+			clog.DebugContext(ctx, "Purged published outbox events", "deleted", deleted)
+		}
+	}
+}
+
+// Relay publishes due events in batches until the outbox has none left or a
+// delivery fails
+func (r *OutboxRelay) Relay(ctx context.Context) error {
+	for {
+		events, err := r.repo.Claim(ctx, relayBatchSize, relayLease)
+		if err != nil {
+			return err
+		}
+		published, err := r.publishBatch(ctx, events)
+		if err != nil || published < relayBatchSize {
+			return err
+		}
+	}
+}
+
+// publishBatch publishes claimed events in order and records the outcome. The
+// first failure, or running out of lease, reschedules that event and the rest.
+func (r *OutboxRelay) publishBatch(ctx context.Context, events []domain.Event) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+	ctx, span := middleware.StartSpan(ctx, "outbox.relay", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Int("events.claimed", len(events)),
+	))
+	defer span.End()
+
+	publishCtx, cancel := context.WithTimeout(ctx, relayLease/2)
+	defer cancel()
+
+	ids := make([]string, 0, len(events))
+	var failed error
+	for _, event := range events {
+		if failed = r.publisher.Publish(publishCtx, event); failed != nil {
+			break
+		}
+		ids = append(ids, event.ID)
+	}
+	outboxDeliveries.WithLabelValues("published").Add(float64(len(ids)))
+	span.SetAttributes(attribute.Int("events.published", len(ids)))
+
+	if len(ids) > 0 {
+		if err := r.repo.MarkPublished(ctx, ids); err != nil {
+			span.RecordError(err)
+			return len(ids), err
+		}
+	}
+	if failed == nil {
+		return len(ids), nil
+	}
+
+	outboxDeliveries.WithLabelValues("failed").Inc()
+	span.RecordError(failed)
+	event := events[len(ids)]
+	clog.WarnContext(ctx, "Failed to publish cart event",
+		"event_id", event.ID, "event_type", string(event.Type), "attempts", event.Attempts, "error", failed)
+
+	rest := make([]string, 0, len(events)-len(ids))
+	for _, e := range events[len(ids):] {
+		rest = append(rest, e.ID)
+	}
+	if err := r.repo.Retry(ctx, rest, time.Now().Add(relayBackoff(event.Attempts)), failed.Error()); err != nil {
+		return len(ids), err
+	}
+	return len(ids), nil
+}
+
+// relayBackoff returns the delay before the next delivery attempt after attempts failures
+func relayBackoff(attempts int) time.Duration {
+	backoff := relayMinBackoff
+	for i := 1; i < attempts && backoff < relayMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, relayMaxBackoff)
+}
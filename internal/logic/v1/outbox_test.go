@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/cart-service/internal/core/events"
+)
+
+// memoryOutbox is an in-memory domain.OutboxRepository
+type memoryOutbox struct {
+	events    []domain.Event
+	published map[string]bool
+	retryAt   map[string]time.Time
+}
+
+func newMemoryOutbox(n int) *memoryOutbox {
+	o := &memoryOutbox{published: map[string]bool{}, retryAt: map[string]time.Time{}}
+	for i := 1; i <= n; i++ {
+		o.events = append(o.events, domain.Event{ID: strconv.Itoa(i), Type: domain.EventCartItemAdded})
+	}
+	return o
+}
+
+func (o *memoryOutbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.Event, error) {
+	var claimed []domain.Event
+	for i := range o.events {
+		e := &o.events[i]
+		if len(claimed) == limit || o.published[e.ID] || o.retryAt[e.ID].After(time.Now()) {
+			continue
+		}
+		e.Attempts++
+		o.retryAt[e.ID] = time.Now().Add(lease)
+		claimed = append(claimed, *e)
+	}
+	return claimed, nil
+}
+
+func (o *memoryOutbox) MarkPublished(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		o.published[id] = true
+	}
+	return nil
+}
+
+func (o *memoryOutbox) Retry(ctx context.Context, ids []string, retryAt time.Time, cause string) error {
+	for _, id := range ids {
+		o.retryAt[id] = retryAt
+	}
+	return nil
+}
+
+func (o *memoryOutbox) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestOutboxRelay(t *testing.T) {
+	ctx := context.Background()
+	outbox := newMemoryOutbox(relayBatchSize + 5)
+	publisher := &events.MemoryPublisher{}
+	relay := NewOutboxRelay(outbox, publisher, time.Second)
+
+	if err := relay.Relay(ctx); err != nil {
+		t.Fatalf("Relay() error = %v", err)
+	}
+	got := publisher.Events()
+	if len(got) != relayBatchSize+5 {
+		t.Fatalf("published %d events, want %d", len(got), relayBatchSize+5)
+	}
+	for i, e := range got {
+		if e.ID != strconv.Itoa(i+1) {
+			t.Fatalf("event %d has ID %s, want outbox order", i, e.ID)
+		}
+	}
+
+	// A failure reschedules the failed event and everything after it
+	outbox = newMemoryOutbox(3)
+	publisher = &events.MemoryPublisher{}
+	publisher.FailWith(errors.New("broker down"))
+	relay = NewOutboxRelay(outbox, publisher, time.Second)
+	if err := relay.Relay(ctx); err != nil {
+		t.Fatalf("Relay() error = %v", err)
+	}
+	for _, e := range outbox.events {
+		if outbox.published[e.ID] || !outbox.retryAt[e.ID].After(time.Now()) {
+			t.Errorf("event %s published=%v retryAt=%v, want rescheduled", e.ID, outbox.published[e.ID], outbox.retryAt[e.ID])
+		}
+	}
+
+	// Retried once due, with the attempt counted again
+	publisher.FailWith(nil)
+	clear(outbox.retryAt)
+	if err := relay.Relay(ctx); err != nil {
+		t.Fatalf("Relay() error = %v", err)
+	}
+	if got := publisher.Events(); len(got) != 3 || got[0].Attempts != 2 {
+		t.Errorf("redelivered %d events (first attempt %d), want 3 on attempt 2", len(got), got[0].Attempts)
+	}
+}
+
+func TestRelayBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{30, relayMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := relayBackoff(tt.attempts); got != tt.want {
+			t.Errorf("relayBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
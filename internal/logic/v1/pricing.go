@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+// priceCart computes item subtotals, the cart subtotal, discounts, shipping, tax and total.
+// Shipping is calculated on the undiscounted subtotal; tax on the discounted item amounts.
+// The repository only returns raw items; all pricing rules live here.
+func (s *CartService) priceCart(ctx context.Context, cart *domain.Cart, req domain.GetCartRequest) error {
+	if cart.Currency == "" {
+		cart.Currency = domain.DefaultCurrency
+	}
+
+	subtotal := domain.Zero(cart.Currency)
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		item.Subtotal = item.ProductPrice.Mul(int64(item.Quantity))
+
+		var err error
+		if subtotal, err = subtotal.Add(item.Subtotal); err != nil {
+			return fmt.Errorf("sum item %s: %w", item.ID, err)
+		}
+	}
+	cart.Subtotal = subtotal
+	cart.ItemCount = len(cart.Items)
+
+	if err := s.applyPromotions(ctx, cart); err != nil {
+		return err
+	}
+
+	shipping, err := s.calculateShipping(ctx, cart, req.Region)
+	if err != nil {
+		return fmt.Errorf("calculate shipping: %w", err)
+	}
+	cart.Shipping = shipping
+
+	if err := s.applyTax(ctx, cart, Jurisdiction{Country: req.Country, State: req.State}); err != nil {
+		return err
+	}
+
+	if cart.Total, err = totalOf(cart); err != nil {
+		return fmt.Errorf("compute total: %w", err)
+	}
+
+	if err := s.applyConversion(ctx, cart, req.Currency); err != nil {
+		return fmt.Errorf("convert totals: %w", err)
+	}
+
+	return nil
+}
+
+// calculateShipping runs the shipping calculator in DefaultCurrency, the currency
+// shipping rules are configured in, and converts the fee back to the cart currency.
+func (s *CartService) calculateShipping(ctx context.Context, cart *domain.Cart, region string) (domain.Money, error) {
+	if cart.Currency == domain.DefaultCurrency {
+		return s.shipping.Calculate(ctx, cart, region)
+	}
+
+	view := *cart
+	subtotal, _, err := s.convert(ctx, cart.Subtotal, domain.DefaultCurrency)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	view.Subtotal = subtotal
+	view.Currency = domain.DefaultCurrency
+
+	fee, err := s.shipping.Calculate(ctx, &view, region)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	converted, _, err := s.convert(ctx, fee, cart.Currency)
+	return converted, err
+}
+
+// applyTax fills the per-item and cart tax from the tax calculator
+func (s *CartService) applyTax(ctx context.Context, cart *domain.Cart, j Jurisdiction) error {
+	result, err := s.tax.Calculate(ctx, cart, j)
+	if err != nil {
+		return fmt.Errorf("calculate tax: %w", err)
+	}
+	if len(result.Items) != len(cart.Items) {
+		return fmt.Errorf("calculate tax: got %d item results for %d items", len(result.Items), len(cart.Items))
+	}
+
+	for i := range cart.Items {
+		cart.Items[i].TaxRate = result.Items[i].Rate
+		cart.Items[i].Tax = result.Items[i].Amount
+	}
+	cart.Tax = result.Total
+	cart.TaxIncluded = result.Inclusive
+	return nil
+}
+
+// totalOf returns subtotal - discount + shipping, plus tax unless prices include it.
+// It reads the cart amounts as they are, so it also works on converted copies.
+func totalOf(cart *domain.Cart) (domain.Money, error) {
+	total, err := cart.Subtotal.Sub(cart.Discount)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	if total, err = total.Add(cart.Shipping); err != nil {
+		return domain.Money{}, err
+	}
+	if cart.TaxIncluded {
+		return total, nil
+	}
+	return total.Add(cart.Tax)
+}
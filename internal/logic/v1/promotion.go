@@ -0,0 +1,275 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/cart-service/middleware"
+	"github.com/duynhne/pkg/logger/clog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithPromotionRepository enables coupons. Without it GetCart applies no discounts
+// and ApplyCoupon reports every code as unknown.
+func WithPromotionRepository(repo domain.PromotionRepository) CartServiceOption {
+	return func(s *CartService) {
+		s.promotions = repo
+	}
+}
+
+// ApplyCoupon attaches a coupon to the cart after checking the cart qualifies,
+// and returns the repriced cart. Applying an attached coupon again is a no-op.
+func (s *CartService) ApplyCoupon(ctx context.Context, userID, code string) (*domain.Cart, error) {
+	ctx, span := middleware.StartSpan(ctx, "cart.coupon.apply", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", userID),
+		attribute.String("coupon.code", code),
+	))
+	defer span.End()
+
+	if s.promotions == nil {
+		return nil, ErrCouponNotFound
+	}
+
+	promotion, err := s.promotions.FindByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrCouponNotFound
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+
+	cart, err := s.GetCart(ctx, userID, domain.GetCartRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := evaluatePromotion(*promotion, cart, time.Now()); err != nil {
+		span.SetAttributes(attribute.Bool("coupon.applied", false))
+		return nil, err
+	}
+
+	if err := s.promotions.Attach(ctx, userID, promotion.ID); err != nil {
+		if errors.Is(err, domain.ErrCouponExhausted) {
+			span.SetAttributes(attribute.Bool("coupon.applied", false))
+			return nil, fmt.Errorf("coupon %s: %w", promotion.Code, ErrCouponUsageLimitReached)
+		}
+		span.RecordError(err)
+		return nil, mutationError(err)
+	}
+
+	span.SetAttributes(attribute.Bool("coupon.applied", true))
+	return s.GetCart(ctx, userID, domain.GetCartRequest{})
+}
+
+// RemoveCoupon detaches a coupon from the cart
+func (s *CartService) RemoveCoupon(ctx context.Context, userID, code string) error {
+	ctx, span := middleware.StartSpan(ctx, "cart.coupon.remove", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", userID),
+		attribute.String("coupon.code", code),
+	))
+	defer span.End()
+
+	if s.promotions == nil {
+		return ErrCouponNotFound
+	}
+
+	if err := s.promotions.Detach(ctx, userID, code); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrCouponNotFound
+		}
+		span.RecordError(err)
+		return mutationError(err)
+	}
+
+	span.SetAttributes(attribute.Bool("coupon.removed", true))
+	return nil
+}
+
+// applyPromotions evaluates the coupons attached to the cart and fills
+// cart.Discounts and cart.Discount. Coupons the cart does not currently
+// qualify for stay attached but grant nothing.
+// Discounts never exceed an item's subtotal or the cart subtotal.
+func (s *CartService) applyPromotions(ctx context.Context, cart *domain.Cart) error {
+	cart.Discount = domain.Zero(cart.Currency)
+	cart.Discounts = []domain.AppliedDiscount{}
+	if s.promotions == nil {
+		return nil
+	}
+
+	promotions, err := s.promotions.ListForCart(ctx, cart.UserID)
+	if err != nil {
+		return fmt.Errorf("list cart promotions: %w", err)
+	}
+
+	remaining := cart.Subtotal
+	itemRemaining := make(map[string]domain.Money, len(cart.Items))
+	for _, item := range cart.Items {
+		itemRemaining[item.ID] = item.Subtotal
+	}
+
+	now := time.Now()
+	for _, p := range promotions {
+		discounts, err := evaluatePromotion(p, cart, now)
+		if err != nil {
+			clog.DebugContext(ctx, "Coupon not applied", "code", p.Code, "reason", err)
+			continue
+		}
+
+		for _, d := range discounts {
+			if d.Scope == domain.DiscountScopeItem {
+				if d.Amount, err = minMoney(d.Amount, itemRemaining[d.ItemID]); err != nil {
+					return fmt.Errorf("apply coupon %s: %w", p.Code, err)
+				}
+			}
+			if d.Amount, err = minMoney(d.Amount, remaining); err != nil {
+				return fmt.Errorf("apply coupon %s: %w", p.Code, err)
+			}
+			if !d.Amount.IsPositive() {
+				continue
+			}
+			if d.Scope == domain.DiscountScopeItem {
+				itemRemaining[d.ItemID], _ = itemRemaining[d.ItemID].Sub(d.Amount)
+			}
+			if remaining, err = remaining.Sub(d.Amount); err != nil {
+				return fmt.Errorf("apply coupon %s: %w", p.Code, err)
+			}
+			if cart.Discount, err = cart.Discount.Add(d.Amount); err != nil {
+				return fmt.Errorf("apply coupon %s: %w", p.Code, err)
+			}
+			cart.Discounts = append(cart.Discounts, d)
+		}
+	}
+	return nil
+}
+
+// evaluatePromotion returns the discounts p grants on a priced cart, or an error
+// wrapping ErrCouponNotApplicable or ErrCouponUsageLimitReached.
+func evaluatePromotion(p domain.Promotion, cart *domain.Cart, now time.Time) ([]domain.AppliedDiscount, error) {
+	switch {
+	case !p.Active, p.StartsAt != nil && now.Before(*p.StartsAt), p.EndsAt != nil && !now.Before(*p.EndsAt):
+		return nil, fmt.Errorf("coupon %s is not active: %w", p.Code, ErrCouponNotApplicable)
+	case p.UsageLimit > 0 && p.UsageCount >= p.UsageLimit:
+		return nil, fmt.Errorf("coupon %s: %w", p.Code, ErrCouponUsageLimitReached)
+	}
+
+	if p.MinSubtotal.IsPositive() {
+		if cmp, err := cart.Subtotal.Cmp(p.MinSubtotal); err != nil || cmp < 0 {
+			return nil, fmt.Errorf("coupon %s requires a subtotal of at least %s: %w",
+				p.Code, p.MinSubtotal, ErrCouponNotApplicable)
+		}
+	}
+
+	var eligible []domain.CartItem
+	for _, item := range cart.Items {
+		if promotionTargets(p, item) {
+			eligible = append(eligible, item)
+		}
+	}
+	if len(eligible) == 0 {
+		return nil, fmt.Errorf("coupon %s: no eligible items: %w", p.Code, ErrCouponNotApplicable)
+	}
+
+	targeted := len(p.ProductIDs) > 0 || len(p.Categories) > 0
+	var discounts []domain.AppliedDiscount
+	orderDiscount := func(amount domain.Money) {
+		discounts = append(discounts, domain.AppliedDiscount{
+			Code: p.Code, Description: p.Description, Scope: domain.DiscountScopeOrder, Amount: amount,
+		})
+	}
+	itemDiscount := func(item domain.CartItem, amount domain.Money) {
+		discounts = append(discounts, domain.AppliedDiscount{
+			Code: p.Code, Description: p.Description, Scope: domain.DiscountScopeItem,
+			ItemID: item.ID, ProductID: item.ProductID, Amount: amount,
+		})
+	}
+
+	switch p.Type {
+	case domain.PromotionPercentOff:
+		if p.PercentOff <= 0 || p.PercentOff > 100 {
+			return nil, fmt.Errorf("coupon %s: invalid percent %d: %w", p.Code, p.PercentOff, ErrCouponNotApplicable)
+		}
+		// Round down so a discount never exceeds the advertised percentage
+		pct := big.NewRat(int64(p.PercentOff), 100)
+		if !targeted {
+			amount, err := cart.Subtotal.MulRat(pct, domain.RoundDown)
+			if err != nil {
+				return nil, err
+			}
+			orderDiscount(amount)
+			break
+		}
+		for _, item := range eligible {
+			amount, err := item.Subtotal.MulRat(pct, domain.RoundDown)
+			if err != nil {
+				return nil, err
+			}
+			itemDiscount(item, amount)
+		}
+
+	case domain.PromotionFixedOff:
+		if p.AmountOff.Currency != cart.Currency {
+			return nil, fmt.Errorf("coupon %s is in %s: %w", p.Code, p.AmountOff.Currency, ErrCouponNotApplicable)
+		}
+		base := domain.Zero(cart.Currency)
+		for _, item := range eligible {
+			base, _ = base.Add(item.Subtotal)
+		}
+		amount, err := minMoney(p.AmountOff, base)
+		if err != nil {
+			return nil, err
+		}
+		orderDiscount(amount)
+
+	case domain.PromotionBuyXGetY:
+		if p.BuyQuantity <= 0 || p.GetQuantity <= 0 {
+			return nil, fmt.Errorf("coupon %s: invalid buy/get quantities: %w", p.Code, ErrCouponNotApplicable)
+		}
+		for _, item := range eligible {
+			free := item.Quantity / (p.BuyQuantity + p.GetQuantity) * p.GetQuantity
+			if free > 0 {
+				itemDiscount(item, item.ProductPrice.Mul(int64(free)))
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("coupon %s: unknown promotion type %q: %w", p.Code, p.Type, ErrCouponNotApplicable)
+	}
+
+	if len(discounts) == 0 {
+		return nil, fmt.Errorf("coupon %s: quantity requirement not met: %w", p.Code, ErrCouponNotApplicable)
+	}
+	return discounts, nil
+}
+
+// promotionTargets reports whether the promotion applies to the item
+func promotionTargets(p domain.Promotion, item domain.CartItem) bool {
+	if len(p.ProductIDs) == 0 && len(p.Categories) == 0 {
+		return true
+	}
+	if slices.Contains(p.ProductIDs, item.ProductID) {
+		return true
+	}
+	return item.ProductCategory != "" && slices.ContainsFunc(p.Categories, func(c string) bool {
+		return strings.EqualFold(c, item.ProductCategory)
+	})
+}
+
+func minMoney(a, b domain.Money) (domain.Money, error) {
+	cmp, err := b.Cmp(a)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	if cmp < 0 {
+		return b, nil
+	}
+	return a, nil
+}
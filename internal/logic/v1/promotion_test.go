@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+// MockPromotionRepository serves a fixed set of promotions
+type MockPromotionRepository struct {
+	promotions map[string]domain.Promotion
+	attached   []string
+	attachErr  error
+}
+
+func (m *MockPromotionRepository) FindByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	p, ok := m.promotions[code]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (m *MockPromotionRepository) ListForCart(ctx context.Context, userID string) ([]domain.Promotion, error) {
+	var out []domain.Promotion
+	for _, code := range m.attached {
+		out = append(out, m.promotions[code])
+	}
+	return out, nil
+}
+
+func (m *MockPromotionRepository) Attach(ctx context.Context, userID, promotionID string) error {
+	if m.attachErr != nil {
+		return m.attachErr
+	}
+	for code, p := range m.promotions {
+		if p.ID == promotionID {
+			m.attached = append(m.attached, code)
+		}
+	}
+	return nil
+}
+
+func (m *MockPromotionRepository) Detach(ctx context.Context, userID, code string) error {
+	return domain.ErrNotFound
+}
+
+func pricedCart(items ...domain.CartItem) *domain.Cart {
+	cart := &domain.Cart{Currency: "USD", Items: items, Subtotal: usd(0)}
+	for i := range cart.Items {
+		cart.Items[i].Subtotal = cart.Items[i].ProductPrice.Mul(int64(cart.Items[i].Quantity))
+		cart.Subtotal, _ = cart.Subtotal.Add(cart.Items[i].Subtotal)
+	}
+	return cart
+}
+
+func TestEvaluatePromotion(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+
+	mouse := domain.CartItem{ID: "1", ProductID: "p1", ProductCategory: "accessories", ProductPrice: usd(2999), Quantity: 3}
+	keyboard := domain.CartItem{ID: "2", ProductID: "p2", ProductCategory: "keyboards", ProductPrice: usd(7999), Quantity: 1}
+	cart := pricedCart(mouse, keyboard) // subtotal 169.96
+
+	tests := []struct {
+		name    string
+		promo   domain.Promotion
+		want    []domain.Money
+		wantErr error
+	}{
+		{"Percent off order", domain.Promotion{Type: domain.PromotionPercentOff, PercentOff: 10, Active: true}, []domain.Money{usd(1699)}, nil},
+		{"Percent off category", domain.Promotion{Type: domain.PromotionPercentOff, PercentOff: 50, Categories: []string{"Keyboards"}, Active: true}, []domain.Money{usd(3999)}, nil},
+		{"Fixed off capped at targeted items", domain.Promotion{Type: domain.PromotionFixedOff, AmountOff: usd(10000), ProductIDs: []string{"p1"}, Active: true}, []domain.Money{usd(8997)}, nil},
+		{"Buy two get one", domain.Promotion{Type: domain.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1, Active: true}, []domain.Money{usd(2999)}, nil},
+		{"Buy X get Y not met", domain.Promotion{Type: domain.PromotionBuyXGetY, BuyQuantity: 1, GetQuantity: 1, ProductIDs: []string{"p2"}, Active: true}, nil, ErrCouponNotApplicable},
+		{"Below min subtotal", domain.Promotion{Type: domain.PromotionPercentOff, PercentOff: 10, MinSubtotal: usd(20000), Active: true}, nil, ErrCouponNotApplicable},
+		{"No eligible items", domain.Promotion{Type: domain.PromotionPercentOff, PercentOff: 10, ProductIDs: []string{"p9"}, Active: true}, nil, ErrCouponNotApplicable},
+		{"Expired", domain.Promotion{Type: domain.PromotionPercentOff, PercentOff: 10, EndsAt: &past, Active: true}, nil, ErrCouponNotApplicable},
+		{"Usage limit", domain.Promotion{Type: domain.PromotionPercentOff, PercentOff: 10, UsageLimit: 5, UsageCount: 5, Active: true}, nil, ErrCouponUsageLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discounts, err := evaluatePromotion(tt.promo, cart, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(discounts) != len(tt.want) {
+				t.Fatalf("got %d discounts, want %d", len(discounts), len(tt.want))
+			}
+			for i, d := range discounts {
+				if d.Amount != tt.want[i] {
+					t.Errorf("discount[%d] = %v, want %v", i, d.Amount, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestApplyCoupon(t *testing.T) {
+	promos := &MockPromotionRepository{promotions: map[string]domain.Promotion{
+		"SAVE10": {ID: "1", Code: "SAVE10", Type: domain.PromotionPercentOff, PercentOff: 10, Active: true},
+		"BIG":    {ID: "2", Code: "BIG", Type: domain.PromotionFixedOff, AmountOff: usd(500), MinSubtotal: usd(100000), Active: true},
+	}}
+	mockRepo := &MockCartRepository{
+		findByUserIDFunc: func(ctx context.Context, userID string) (*domain.Cart, error) {
+			return &domain.Cart{UserID: userID, Currency: "USD", Items: []domain.CartItem{
+				{ID: "1", ProductID: "p1", ProductPrice: usd(5000), Quantity: 2},
+			}}, nil
+		},
+	}
+	service := NewCartService(mockRepo, WithPromotionRepository(promos))
+
+	cart, err := service.ApplyCoupon(context.Background(), "user1", "SAVE10")
+	if err != nil {
+		t.Fatalf("ApplyCoupon() error = %v", err)
+	}
+	if cart.Discount != usd(1000) || len(cart.Discounts) != 1 {
+		t.Errorf("Discount = %v (%d lines), want 10.00 (1 line)", cart.Discount, len(cart.Discounts))
+	}
+	// 100.00 - 10.00 + 5.00 shipping
+	if cart.Total != usd(9500) {
+		t.Errorf("Total = %v, want 95.00", cart.Total)
+	}
+
+	if _, err := service.ApplyCoupon(context.Background(), "user1", "BIG"); !errors.Is(err, ErrCouponNotApplicable) {
+		t.Errorf("BIG error = %v, want ErrCouponNotApplicable", err)
+	}
+	if _, err := service.ApplyCoupon(context.Background(), "user1", "NOPE"); !errors.Is(err, ErrCouponNotFound) {
+		t.Errorf("NOPE error = %v, want ErrCouponNotFound", err)
+	}
+
+	promos.attachErr = domain.ErrCouponExhausted
+	if _, err := service.ApplyCoupon(context.Background(), "user1", "SAVE10"); !errors.Is(err, ErrCouponUsageLimitReached) {
+		t.Errorf("exhausted SAVE10 error = %v, want ErrCouponUsageLimitReached", err)
+	}
+}
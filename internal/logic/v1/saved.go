@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"context"
+	"errors"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/cart-service/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The saved-for-later list holds items taken out of the cart without losing
+// them. A product lives in exactly one of the two lists: items only move between
+// them through moveItem, which deletes the source line and upserts the target in
+// one repository transaction, and AddToCart takes the product off the saved list.
+
+// ListSaved returns the user's saved-for-later items
+func (s *CartService) ListSaved(ctx context.Context, userID string) ([]domain.CartItem, error) {
+	ctx, span := middleware.StartSpan(ctx, "cart.saved.list", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", userID),
+	))
+	defer span.End()
+
+	// Call repository
+	items, err := s.cartRepo.ListSaved(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if items == nil {
+		items = []domain.CartItem{}
+	}
+
+	span.SetAttributes(attribute.Int("items.count", len(items)))
+	return items, nil
+}
+
+// SaveForLater moves a cart item to the saved list and returns it as saved
+func (s *CartService) SaveForLater(ctx context.Context, userID, itemID string) (*domain.CartItem, error) {
+	return s.moveItem(ctx, userID, itemID, domain.ListSaved)
+}
+
+// MoveToCart moves a saved item back into the cart and returns it as stored in the cart
+func (s *CartService) MoveToCart(ctx context.Context, userID, itemID string) (*domain.CartItem, error) {
+	return s.moveItem(ctx, userID, itemID, domain.ListCart)
+}
+
+// moveItem moves itemID into the list to, out of the other one
+func (s *CartService) moveItem(ctx context.Context, userID, itemID string, to domain.ItemList) (*domain.CartItem, error) {
+	ctx, span := middleware.StartSpan(ctx, "cart.saved.move", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("item.id", itemID),
+		attribute.String("list.target", string(to)),
+	))
+	defer span.End()
+
+	if s.inventory != nil && to == domain.ListCart {
+		if err := s.checkMoveStock(ctx, userID, itemID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Call repository
+	item, err := s.cartRepo.MoveItem(ctx, userID, itemID, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound) && to == domain.ListSaved:
+			return nil, ErrCartItemNotFound
+		case errors.Is(err, domain.ErrNotFound):
+			return nil, ErrSavedItemNotFound
+		case errors.Is(err, domain.ErrCurrencyMismatch):
+			return nil, ErrMixedCurrency
+		}
+		span.RecordError(err)
+		return nil, mutationError(err)
+	}
+	s.holdStock(ctx, userID)
+
+	span.AddEvent("cart.item.moved")
+	return item, nil
+}
+
+// RemoveSavedItem deletes an item from the saved list
+func (s *CartService) RemoveSavedItem(ctx context.Context, userID, itemID string) error {
+	ctx, span := middleware.StartSpan(ctx, "cart.saved.remove", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("item.id", itemID),
+	))
+	defer span.End()
+
+	// Call repository
+	if err := s.cartRepo.RemoveSaved(ctx, userID, itemID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrSavedItemNotFound
+		}
+		span.RecordError(err)
+		return err
+	}
+
+	span.AddEvent("cart.saved.removed")
+	return nil
+}
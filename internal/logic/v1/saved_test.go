@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+func TestMoveItemErrors(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		move    func(s *CartService) error
+		repoErr error
+		want    error
+	}{
+		{
+			name:    "cart item missing",
+			move:    func(s *CartService) error { _, err := s.SaveForLater(ctx, "user1", "9"); return err },
+			repoErr: domain.ErrNotFound,
+			want:    ErrCartItemNotFound,
+		},
+		{
+			name:    "saved item missing",
+			move:    func(s *CartService) error { _, err := s.MoveToCart(ctx, "user1", "9"); return err },
+			repoErr: domain.ErrNotFound,
+			want:    ErrSavedItemNotFound,
+		},
+		{
+			name:    "currency differs from cart",
+			move:    func(s *CartService) error { _, err := s.MoveToCart(ctx, "user1", "9"); return err },
+			repoErr: domain.ErrCurrencyMismatch,
+			want:    ErrMixedCurrency,
+		},
+		{
+			name:    "stale If-Match",
+			move:    func(s *CartService) error { _, err := s.SaveForLater(ctx, "user1", "9"); return err },
+			repoErr: domain.ErrVersionMismatch,
+			want:    ErrCartModified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewCartService(&MockCartRepository{
+				moveItemFunc: func(ctx context.Context, userID, itemID string, to domain.ItemList) (*domain.CartItem, error) {
+					return nil, tt.repoErr
+				},
+			})
+			if err := tt.move(service); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoveItemTargets(t *testing.T) {
+	ctx := context.Background()
+
+	var got []domain.ItemList
+	service := NewCartService(&MockCartRepository{
+		moveItemFunc: func(ctx context.Context, userID, itemID string, to domain.ItemList) (*domain.CartItem, error) {
+			got = append(got, to)
+			return &domain.CartItem{ID: itemID}, nil
+		},
+	})
+
+	if _, err := service.SaveForLater(ctx, "user1", "1"); err != nil {
+		t.Fatalf("SaveForLater() error = %v", err)
+	}
+	if _, err := service.MoveToCart(ctx, "user1", "2"); err != nil {
+		t.Fatalf("MoveToCart() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != domain.ListSaved || got[1] != domain.ListCart {
+		t.Errorf("targets = %v, want [saved cart]", got)
+	}
+
+	items, err := service.ListSaved(ctx, "user1")
+	if err != nil || items == nil {
+		t.Errorf("ListSaved() = %v, %v, want empty non-nil list", items, err)
+	}
+}
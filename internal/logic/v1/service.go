@@ -3,39 +3,124 @@ package v1
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/duynhne/cart-service/internal/core/domain"
 	"github.com/duynhne/cart-service/middleware"
+	"github.com/duynhne/pkg/logger/clog"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
 
 // CartService handles cart business logic
 type CartService struct {
-	cartRepo domain.CartRepository
+	cartRepo   domain.CartRepository
+	shipping   ShippingCalculator
+	tax        TaxCalculator
+	rates      RateProvider
+	promotions domain.PromotionRepository
+	catalog    domain.ProductCatalog
+	mergeWith  domain.MergePolicy
+
+	inventory      domain.InventoryClient
+	reservations   domain.ReservationRepository
+	reservationTTL time.Duration
+
+	checkout domain.CheckoutRepository
+	lockTTL  time.Duration
+
+	maxBatchOps int
+}
+
+// CartServiceOption configures optional CartService collaborators
+type CartServiceOption func(*CartService)
+
+// WithShippingCalculator overrides the default flat-rate shipping calculator
+func WithShippingCalculator(calc ShippingCalculator) CartServiceOption {
+	return func(s *CartService) {
+		s.shipping = calc
+	}
+}
+
+// WithTaxCalculator overrides the default calculator, which charges no tax
+func WithTaxCalculator(calc TaxCalculator) CartServiceOption {
+	return func(s *CartService) {
+		s.tax = calc
+	}
+}
+
+// WithRateProvider enables currency conversion of cart totals
+func WithRateProvider(rates RateProvider) CartServiceOption {
+	return func(s *CartService) {
+		s.rates = rates
+	}
+}
+
+// WithMergePolicy sets the policy used when a merge request does not name one
+func WithMergePolicy(policy domain.MergePolicy) CartServiceOption {
+	return func(s *CartService) {
+		s.mergeWith = policy
+	}
 }
 
 // NewCartService creates a new CartService with repository injection
-func NewCartService(repo domain.CartRepository) *CartService {
-	return &CartService{cartRepo: repo}
+func NewCartService(repo domain.CartRepository, opts ...CartServiceOption) *CartService {
+	s := &CartService{
+		cartRepo:    repo,
+		shipping:    FlatRateShipping{Rate: DefaultShippingRate},
+		tax:         NoTax{},
+		mergeWith:   domain.MergeSum,
+		maxBatchOps: DefaultMaxBatchOperations,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// GetCart retrieves the cart for a user
-func (s *CartService) GetCart(ctx context.Context, userID string) (*domain.Cart, error) {
+// GetCart retrieves the cart for a user and computes its totals
+func (s *CartService) GetCart(ctx context.Context, userID string, req domain.GetCartRequest) (*domain.Cart, error) {
 	ctx, span := middleware.StartSpan(ctx, "cart.get", trace.WithAttributes(
 		attribute.String("layer", "logic"),
 		attribute.String("user.id", userID),
 	))
 	defer span.End()
 
+	cart, err := s.loadPricedCart(ctx, userID, req, false)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("items.count", len(cart.Items)),
+		attribute.Float64("cart.shipping", cart.Shipping.Float64()),
+	)
+	return cart, nil
+}
+
+// loadPricedCart loads the user's cart, reprices it from the catalog and
+// computes its totals. An unreachable catalog serves the stored prices, unless
+// strictPrices is set, in which case it fails with ErrCatalogUnavailable.
+func (s *CartService) loadPricedCart(ctx context.Context, userID string, req domain.GetCartRequest, strictPrices bool) (*domain.Cart, error) {
 	// Call repository
 	cart, err := s.cartRepo.FindByUserID(ctx, userID)
 	if err != nil {
-		span.RecordError(err)
 		return nil, err
 	}
 
-	span.SetAttributes(attribute.Int("items.count", len(cart.Items)))
+	if err := s.refreshPrices(ctx, cart); err != nil {
+		if strictPrices {
+			return nil, err
+		}
+		clog.WarnContext(ctx, "Product catalog unavailable, serving stored prices", "error", err)
+	}
+	s.annotateStock(ctx, userID, cart)
+	if err := s.priceCart(ctx, cart, req); err != nil {
+		return nil, err
+	}
 	return cart, nil
 }
 
@@ -58,6 +143,38 @@ func (s *CartService) GetCartCount(ctx context.Context, userID string) (int, err
 	return count, nil
 }
 
+// UpdateCart changes the cart-level fields in req, such as the name and notes,
+// and returns the updated cart
+func (s *CartService) UpdateCart(ctx context.Context, userID string, req domain.UpdateCartRequest) (*domain.Cart, error) {
+	ctx, span := middleware.StartSpan(ctx, "cart.update_header", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", userID),
+	))
+	defer span.End()
+
+	var update domain.CartUpdate
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("blank cart name: %w", domain.ErrInvalidInput)
+		}
+		update.Name = &name
+	}
+	if req.Notes != nil {
+		notes := strings.TrimSpace(*req.Notes)
+		update.Notes = &notes
+	}
+
+	// Call repository
+	if err := s.cartRepo.UpdateCart(ctx, userID, update); err != nil {
+		span.RecordError(err)
+		return nil, mutationError(err)
+	}
+
+	span.AddEvent("cart.updated")
+	return s.GetCart(ctx, userID, domain.GetCartRequest{})
+}
+
 // AddToCart adds an item to the cart
 func (s *CartService) AddToCart(ctx context.Context, userID string, req domain.AddToCartRequest) (*domain.CartItem, error) {
 	ctx, span := middleware.StartSpan(ctx, "cart.add", trace.WithAttributes(
@@ -66,26 +183,29 @@ func (s *CartService) AddToCart(ctx context.Context, userID string, req domain.A
 	))
 	defer span.End()
 
-	// Business validation
-	if req.Quantity <= 0 {
+	item, err := s.newCartItem(ctx, req)
+	if err != nil {
 		span.SetAttributes(attribute.Bool("item.added", false))
-		return nil, ErrInvalidQuantity
+		return nil, err
 	}
 
-	// Create cart item with product details
-	item := domain.CartItem{
-		ProductID:    req.ProductID,
-		ProductName:  req.ProductName,
-		ProductPrice: req.ProductPrice,
-		Quantity:     req.Quantity,
+	if s.inventory != nil {
+		if err := s.checkAddStock(ctx, userID, item.ProductID, item.Quantity); err != nil {
+			span.SetAttributes(attribute.Bool("item.added", false))
+			return nil, err
+		}
 	}
 
 	// Call repository
-	err := s.cartRepo.AddItem(ctx, userID, &item)
+	err = s.cartRepo.AddItem(ctx, userID, &item)
 	if err != nil {
+		if errors.Is(err, domain.ErrCurrencyMismatch) {
+			return nil, ErrMixedCurrency
+		}
 		span.RecordError(err)
-		return nil, err
+		return nil, mutationError(err)
 	}
+	s.holdStock(ctx, userID)
 
 	span.SetAttributes(attribute.Bool("item.added", true))
 	span.AddEvent("cart.item.added")
@@ -93,6 +213,44 @@ func (s *CartService) AddToCart(ctx context.Context, userID string, req domain.A
 	return &item, nil
 }
 
+// newCartItem validates req and builds the cart item it adds
+func (s *CartService) newCartItem(ctx context.Context, req domain.AddToCartRequest) (domain.CartItem, error) {
+	// Business validation
+	if req.Quantity <= 0 {
+		return domain.CartItem{}, ErrInvalidQuantity
+	}
+	if s.catalog != nil {
+		return s.catalogItem(ctx, req)
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	if strings.TrimSpace(req.ProductName) == "" {
+		return domain.CartItem{}, ErrInvalidProductName
+	}
+	if !req.ProductPrice.IsPositive() ||
+		(req.ProductPrice.Currency != "" && req.ProductPrice.Currency != currency) {
+		return domain.CartItem{}, ErrInvalidPrice
+	}
+
+	taxClass := req.TaxClass
+	if taxClass == "" {
+		taxClass = DefaultTaxClass
+	}
+
+	// Create cart item with the product details sent by the client
+	return domain.CartItem{
+		ProductID:       req.ProductID,
+		ProductName:     req.ProductName,
+		ProductCategory: req.ProductCategory,
+		TaxClass:        strings.ToLower(taxClass),
+		ProductPrice:    domain.NewMoney(req.ProductPrice.Amount, currency),
+		Quantity:        req.Quantity,
+	}, nil
+}
+
 // UpdateItemQuantity updates the quantity of a cart item
 func (s *CartService) UpdateItemQuantity(ctx context.Context, userID, itemID string, quantity int) error {
 	ctx, span := middleware.StartSpan(ctx, "cart.update", trace.WithAttributes(
@@ -106,6 +264,12 @@ func (s *CartService) UpdateItemQuantity(ctx context.Context, userID, itemID str
 		span.SetAttributes(attribute.Bool("item.updated", false))
 		return ErrInvalidQuantity
 	}
+	if s.inventory != nil {
+		if err := s.checkUpdateStock(ctx, userID, itemID, quantity); err != nil {
+			span.SetAttributes(attribute.Bool("item.updated", false))
+			return err
+		}
+	}
 
 	// Call repository
 	err := s.cartRepo.UpdateItem(ctx, userID, itemID, quantity)
@@ -114,8 +278,9 @@ func (s *CartService) UpdateItemQuantity(ctx context.Context, userID, itemID str
 			return ErrCartItemNotFound
 		}
 		span.RecordError(err)
-		return err
+		return mutationError(err)
 	}
+	s.holdStock(ctx, userID)
 
 	span.SetAttributes(attribute.Bool("item.updated", true))
 	return nil
@@ -136,8 +301,9 @@ func (s *CartService) RemoveItem(ctx context.Context, userID, itemID string) err
 			return ErrCartItemNotFound
 		}
 		span.RecordError(err)
-		return err
+		return mutationError(err)
 	}
+	s.holdStock(ctx, userID)
 
 	span.SetAttributes(attribute.Bool("item.removed", true))
 	span.AddEvent("cart.item.removed")
@@ -156,10 +322,55 @@ func (s *CartService) ClearCart(ctx context.Context, userID string) error {
 	err := s.cartRepo.Clear(ctx, userID)
 	if err != nil {
 		span.RecordError(err)
-		return err
+		return mutationError(err)
 	}
 
 	span.SetAttributes(attribute.Bool("cart.cleared", true))
 	span.AddEvent("cart.cleared")
 	return nil
 }
+
+// MergeGuestCart merges the guest cart identified by guestID into the user's cart
+// and returns the merged cart. The guest cart is deleted.
+func (s *CartService) MergeGuestCart(ctx context.Context, userID, guestID string, policy domain.MergePolicy) (*domain.Cart, error) {
+	ctx, span := middleware.StartSpan(ctx, "cart.merge", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("user.id", userID),
+	))
+	defer span.End()
+
+	if domain.IsGuestOwner(userID) {
+		return nil, ErrUnauthorized
+	}
+	if guestID == "" || !domain.IsGuestOwner(guestID) {
+		return nil, ErrNoGuestCart
+	}
+	if policy == "" {
+		policy = s.mergeWith
+	}
+	span.SetAttributes(attribute.String("merge.policy", string(policy)))
+
+	// Call repository
+	if err := s.cartRepo.Merge(ctx, guestID, userID, policy); err != nil {
+		if errors.Is(err, domain.ErrCurrencyMismatch) {
+			return nil, ErrMixedCurrency
+		}
+		span.RecordError(err)
+		return nil, mutationError(err)
+	}
+	s.holdStock(ctx, userID)
+
+	span.AddEvent("cart.merged")
+	return s.GetCart(ctx, userID, domain.GetCartRequest{})
+}
+
+// mutationError translates repository errors common to every cart mutation
+func mutationError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrVersionMismatch):
+		return ErrCartModified
+	case errors.Is(err, domain.ErrCartNotFound):
+		return ErrCartNotFound
+	}
+	return err
+}
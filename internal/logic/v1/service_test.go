@@ -2,6 +2,7 @@ package v1
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/duynhne/cart-service/internal/core/domain"
@@ -9,16 +10,50 @@ import (
 
 // MockCartRepository
 type MockCartRepository struct {
-	addItemFunc func(ctx context.Context, userID string, item *domain.CartItem) error
-	clearFunc   func(ctx context.Context, userID string) error
+	findByUserIDFunc func(ctx context.Context, userID string) (*domain.Cart, error)
+	updateCartFunc   func(ctx context.Context, userID string, update domain.CartUpdate) error
+	createCartFunc   func(ctx context.Context, userID, name string, kind domain.CartKind) (*domain.CartSummary, error)
+	deleteCartFunc   func(ctx context.Context, userID, cartID string) error
+	addItemFunc      func(ctx context.Context, userID string, item *domain.CartItem) error
+	clearFunc        func(ctx context.Context, userID string) error
+	moveItemFunc     func(ctx context.Context, userID, itemID string, to domain.ItemList) (*domain.CartItem, error)
+	mergeFunc        func(ctx context.Context, guestID, userID string, policy domain.MergePolicy) error
+	applyBatchFunc   func(ctx context.Context, userID string, ops []domain.BatchOp, atomic bool) ([]error, error)
 }
 
 func (m *MockCartRepository) FindByUserID(ctx context.Context, userID string) (*domain.Cart, error) {
+	if m.findByUserIDFunc != nil {
+		return m.findByUserIDFunc(ctx, userID)
+	}
 	return nil, nil
 }
 func (m *MockCartRepository) GetItemCount(ctx context.Context, userID string) (int, error) {
 	return 0, nil
 }
+func (m *MockCartRepository) UpdateCart(ctx context.Context, userID string, update domain.CartUpdate) error {
+	if m.updateCartFunc != nil {
+		return m.updateCartFunc(ctx, userID, update)
+	}
+	return nil
+}
+func (m *MockCartRepository) ListCarts(ctx context.Context, userID string) ([]domain.CartSummary, error) {
+	return nil, nil
+}
+func (m *MockCartRepository) CreateCart(ctx context.Context, userID, name string, kind domain.CartKind) (*domain.CartSummary, error) {
+	if m.createCartFunc != nil {
+		return m.createCartFunc(ctx, userID, name, kind)
+	}
+	return &domain.CartSummary{Name: name, Kind: kind}, nil
+}
+func (m *MockCartRepository) DeleteCart(ctx context.Context, userID, cartID string) error {
+	if m.deleteCartFunc != nil {
+		return m.deleteCartFunc(ctx, userID, cartID)
+	}
+	return nil
+}
+func (m *MockCartRepository) SetActiveCart(ctx context.Context, userID, cartID string) error {
+	return nil
+}
 func (m *MockCartRepository) AddItem(ctx context.Context, userID string, item *domain.CartItem) error {
 	if m.addItemFunc != nil {
 		return m.addItemFunc(ctx, userID, item)
@@ -37,6 +72,30 @@ func (m *MockCartRepository) Clear(ctx context.Context, userID string) error {
 	}
 	return nil
 }
+func (m *MockCartRepository) ListSaved(ctx context.Context, userID string) ([]domain.CartItem, error) {
+	return nil, nil
+}
+func (m *MockCartRepository) MoveItem(ctx context.Context, userID, itemID string, to domain.ItemList) (*domain.CartItem, error) {
+	if m.moveItemFunc != nil {
+		return m.moveItemFunc(ctx, userID, itemID, to)
+	}
+	return nil, nil
+}
+func (m *MockCartRepository) RemoveSaved(ctx context.Context, userID, itemID string) error {
+	return nil
+}
+func (m *MockCartRepository) Merge(ctx context.Context, guestID, userID string, policy domain.MergePolicy) error {
+	if m.mergeFunc != nil {
+		return m.mergeFunc(ctx, guestID, userID, policy)
+	}
+	return nil
+}
+func (m *MockCartRepository) ApplyBatch(ctx context.Context, userID string, ops []domain.BatchOp, atomic bool) ([]error, error) {
+	if m.applyBatchFunc != nil {
+		return m.applyBatchFunc(ctx, userID, ops, atomic)
+	}
+	return make([]error, len(ops)), nil
+}
 
 func TestAddToCart(t *testing.T) {
 	ctx := context.Background()
@@ -51,7 +110,7 @@ func TestAddToCart(t *testing.T) {
 			req: domain.AddToCartRequest{
 				ProductID:    "p1",
 				ProductName:  "Product 1",
-				ProductPrice: 100.0,
+				ProductPrice: domain.NewMoney(10000, domain.DefaultCurrency),
 				Quantity:     1,
 			},
 			wantErr: false,
@@ -61,7 +120,7 @@ func TestAddToCart(t *testing.T) {
 			req: domain.AddToCartRequest{
 				ProductID:    "p1",
 				ProductName:  "Product 1",
-				ProductPrice: 100.0,
+				ProductPrice: domain.NewMoney(10000, domain.DefaultCurrency),
 				Quantity:     0,
 			},
 			wantErr: true,
@@ -81,6 +140,25 @@ func TestAddToCart(t *testing.T) {
 	}
 }
 
+func TestAddToCartFull(t *testing.T) {
+	mockRepo := &MockCartRepository{
+		addItemFunc: func(ctx context.Context, userID string, item *domain.CartItem) error {
+			return domain.ErrCartFull
+		},
+	}
+	service := NewCartService(mockRepo)
+
+	_, err := service.AddToCart(context.Background(), "user1", domain.AddToCartRequest{
+		ProductID:    "p1",
+		ProductName:  "Product 1",
+		ProductPrice: domain.NewMoney(10000, domain.DefaultCurrency),
+		Quantity:     1,
+	})
+	if !errors.Is(err, domain.ErrCartFull) {
+		t.Fatalf("AddToCart() error = %v, want ErrCartFull", err)
+	}
+}
+
 func TestClearCart(t *testing.T) {
 	ctx := context.Background()
 
@@ -106,3 +184,84 @@ func TestClearCart(t *testing.T) {
 		t.Fatalf("ClearCart() userID = %q, want %q", gotUserID, "user1")
 	}
 }
+
+func TestMergeGuestCart(t *testing.T) {
+	ctx := context.Background()
+
+	var gotPolicy domain.MergePolicy
+	mockRepo := &MockCartRepository{
+		findByUserIDFunc: func(ctx context.Context, userID string) (*domain.Cart, error) {
+			return &domain.Cart{UserID: userID}, nil
+		},
+		mergeFunc: func(ctx context.Context, guestID, userID string, policy domain.MergePolicy) error {
+			gotPolicy = policy
+			return nil
+		},
+	}
+	service := NewCartService(mockRepo, WithMergePolicy(domain.MergeMax))
+
+	if _, err := service.MergeGuestCart(ctx, "user1", domain.GuestOwnerID("abc"), ""); err != nil {
+		t.Fatalf("MergeGuestCart() error = %v", err)
+	}
+	if gotPolicy != domain.MergeMax {
+		t.Errorf("policy = %q, want default %q", gotPolicy, domain.MergeMax)
+	}
+
+	if _, err := service.MergeGuestCart(ctx, "user1", "", ""); !errors.Is(err, ErrNoGuestCart) {
+		t.Errorf("missing guest cart error = %v, want ErrNoGuestCart", err)
+	}
+	if _, err := service.MergeGuestCart(ctx, domain.GuestOwnerID("x"), domain.GuestOwnerID("abc"), ""); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("guest caller error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestUpdateCart(t *testing.T) {
+	ctx := context.Background()
+
+	var got domain.CartUpdate
+	mockRepo := &MockCartRepository{
+		findByUserIDFunc: func(ctx context.Context, userID string) (*domain.Cart, error) {
+			return &domain.Cart{UserID: userID, Notes: *got.Notes}, nil
+		},
+		updateCartFunc: func(ctx context.Context, userID string, update domain.CartUpdate) error {
+			got = update
+			return nil
+		},
+	}
+	service := NewCartService(mockRepo)
+
+	notes := "  leave at the door  "
+	cart, err := service.UpdateCart(ctx, "user1", domain.UpdateCartRequest{Notes: &notes})
+	if err != nil {
+		t.Fatalf("UpdateCart() error = %v", err)
+	}
+	if got.Notes == nil || *got.Notes != "leave at the door" {
+		t.Errorf("repository notes = %v, want trimmed notes", got.Notes)
+	}
+	if cart.Notes != "leave at the door" {
+		t.Errorf("cart notes = %q, want updated notes", cart.Notes)
+	}
+
+	blank := "   "
+	if _, err := service.UpdateCart(ctx, "user1", domain.UpdateCartRequest{Name: &blank}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("UpdateCart() with a blank name error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestMutationsReportVersionMismatch(t *testing.T) {
+	ctx := domain.WithExpectedVersions(context.Background(), 3)
+
+	mockRepo := &MockCartRepository{
+		clearFunc: func(ctx context.Context, userID string) error {
+			if !domain.VersionMatches(ctx, 4) {
+				return domain.ErrVersionMismatch
+			}
+			return nil
+		},
+	}
+	service := NewCartService(mockRepo)
+
+	if err := service.ClearCart(ctx, "user1"); !errors.Is(err, ErrCartModified) {
+		t.Errorf("ClearCart() error = %v, want ErrCartModified", err)
+	}
+}
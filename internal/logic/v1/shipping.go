@@ -0,0 +1,232 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/duynhne/cart-service/config"
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+// DefaultShippingRate is the flat fee applied when no shipping strategy is configured.
+var DefaultShippingRate = domain.NewMoney(500, domain.DefaultCurrency)
+
+// Shipping rule types accepted by SHIPPING_STRATEGY and the shipping rules file.
+const (
+	ShippingTypeFlat              = "flat"
+	ShippingTypeFreeOverThreshold = "free_over_threshold"
+	ShippingTypeQuantityTiers     = "quantity_tiers"
+	ShippingTypeRules             = "rules"
+)
+
+// ShippingCalculator computes the shipping fee for a priced cart.
+// The cart passed in already has item subtotals and Subtotal populated.
+// Region is the caller-supplied shipping region (may be empty).
+type ShippingCalculator interface {
+	Calculate(ctx context.Context, cart *domain.Cart, region string) (domain.Money, error)
+}
+
+// FlatRateShipping charges the same fee for every non-empty cart.
+type FlatRateShipping struct {
+	Rate domain.Money
+}
+
+// Calculate implements ShippingCalculator.
+func (s FlatRateShipping) Calculate(_ context.Context, cart *domain.Cart, _ string) (domain.Money, error) {
+	if len(cart.Items) == 0 {
+		return domain.Zero(s.Rate.Currency), nil
+	}
+	return s.Rate, nil
+}
+
+// FreeShippingOverThreshold charges Rate unless the cart subtotal reaches Threshold.
+type FreeShippingOverThreshold struct {
+	Rate      domain.Money
+	Threshold domain.Money
+}
+
+// Calculate implements ShippingCalculator.
+func (s FreeShippingOverThreshold) Calculate(_ context.Context, cart *domain.Cart, _ string) (domain.Money, error) {
+	if len(cart.Items) == 0 {
+		return domain.Zero(s.Rate.Currency), nil
+	}
+	cmp, err := cart.Subtotal.Cmp(s.Threshold)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	if cmp >= 0 {
+		return domain.Zero(s.Rate.Currency), nil
+	}
+	return s.Rate, nil
+}
+
+// ShippingTier is a single step of a quantity-based shipping table.
+type ShippingTier struct {
+	MinQuantity int          `json:"min_quantity"`
+	Rate        domain.Money `json:"rate"`
+}
+
+// QuantityTierShipping picks the rate of the highest tier whose MinQuantity
+// does not exceed the total number of units in the cart.
+// Carts carry no weight data, so tiers are expressed in units.
+type QuantityTierShipping struct {
+	tiers []ShippingTier
+}
+
+// NewQuantityTierShipping validates and sorts the tiers.
+// The lowest tier must start at one unit so every non-empty cart is covered.
+func NewQuantityTierShipping(tiers []ShippingTier) (*QuantityTierShipping, error) {
+	if len(tiers) == 0 {
+		return nil, errors.New("quantity tiers: at least one tier is required")
+	}
+	sorted := make([]ShippingTier, len(tiers))
+	copy(sorted, tiers)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].MinQuantity < sorted[j].MinQuantity })
+
+	if sorted[0].MinQuantity > 1 {
+		return nil, fmt.Errorf("quantity tiers: lowest tier must start at 1, got %d", sorted[0].MinQuantity)
+	}
+	for _, t := range sorted {
+		if t.Rate.IsNegative() {
+			return nil, fmt.Errorf("quantity tiers: negative rate %s", t.Rate)
+		}
+	}
+	return &QuantityTierShipping{tiers: sorted}, nil
+}
+
+// Calculate implements ShippingCalculator.
+func (s *QuantityTierShipping) Calculate(_ context.Context, cart *domain.Cart, _ string) (domain.Money, error) {
+	units := 0
+	for _, item := range cart.Items {
+		units += item.Quantity
+	}
+	if units == 0 {
+		return domain.Zero(s.tiers[0].Rate.Currency), nil
+	}
+
+	rate := s.tiers[0].Rate
+	for _, t := range s.tiers {
+		if units < t.MinQuantity {
+			break
+		}
+		rate = t.Rate
+	}
+	return rate, nil
+}
+
+// RegionalShipping delegates to a per-region calculator, falling back to Default
+// for unknown or empty regions. Region keys are matched case-insensitively.
+type RegionalShipping struct {
+	Default ShippingCalculator
+	Regions map[string]ShippingCalculator
+}
+
+// Calculate implements ShippingCalculator.
+func (s *RegionalShipping) Calculate(ctx context.Context, cart *domain.Cart, region string) (domain.Money, error) {
+	if calc, ok := s.Regions[strings.ToUpper(region)]; ok {
+		return calc.Calculate(ctx, cart, region)
+	}
+	return s.Default.Calculate(ctx, cart, region)
+}
+
+// ShippingRule is the JSON representation of a single shipping strategy.
+type ShippingRule struct {
+	Type      string         `json:"type"`
+	Rate      domain.Money   `json:"rate"`
+	Threshold domain.Money   `json:"threshold"`
+	Tiers     []ShippingTier `json:"tiers"`
+}
+
+// ShippingRules is the layout of the file referenced by SHIPPING_RULES_FILE.
+// Amounts may be JSON numbers or decimal strings and are read in DefaultCurrency.
+//
+// Example:
+//
+//	{
+//	  "default": {"type": "flat", "rate": 5.00},
+//	  "regions": {
+//	    "EU":   {"type": "free_over_threshold", "rate": 7.50, "threshold": 100},
+//	    "APAC": {"type": "quantity_tiers", "tiers": [{"min_quantity": 1, "rate": 6}, {"min_quantity": 5, "rate": 0}]}
+//	  }
+//	}
+type ShippingRules struct {
+	Default ShippingRule            `json:"default"`
+	Regions map[string]ShippingRule `json:"regions"`
+}
+
+// calculator builds the ShippingCalculator described by the rule.
+func (r ShippingRule) calculator() (ShippingCalculator, error) {
+	if r.Rate.IsNegative() {
+		return nil, fmt.Errorf("%s: negative rate %s", r.Type, r.Rate)
+	}
+	switch r.Type {
+	case ShippingTypeFlat:
+		return FlatRateShipping{Rate: r.Rate}, nil
+	case ShippingTypeFreeOverThreshold:
+		if !r.Threshold.IsPositive() {
+			return nil, fmt.Errorf("%s: threshold must be positive", r.Type)
+		}
+		return FreeShippingOverThreshold{Rate: r.Rate, Threshold: r.Threshold}, nil
+	case ShippingTypeQuantityTiers:
+		return NewQuantityTierShipping(r.Tiers)
+	default:
+		return nil, fmt.Errorf("unknown shipping rule type %q", r.Type)
+	}
+}
+
+// Calculator builds a RegionalShipping calculator from the rule table.
+func (r ShippingRules) Calculator() (*RegionalShipping, error) {
+	def, err := r.Default.calculator()
+	if err != nil {
+		return nil, fmt.Errorf("default rule: %w", err)
+	}
+
+	regional := &RegionalShipping{
+		Default: def,
+		Regions: make(map[string]ShippingCalculator, len(r.Regions)),
+	}
+	for region, rule := range r.Regions {
+		calc, err := rule.calculator()
+		if err != nil {
+			return nil, fmt.Errorf("region %q: %w", region, err)
+		}
+		regional.Regions[strings.ToUpper(region)] = calc
+	}
+	return regional, nil
+}
+
+// LoadShippingRules reads and validates a shipping rules file.
+func LoadShippingRules(path string) (*RegionalShipping, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from operator config
+	if err != nil {
+		return nil, fmt.Errorf("read shipping rules: %w", err)
+	}
+
+	var rules ShippingRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse shipping rules: %w", err)
+	}
+
+	return rules.Calculator()
+}
+
+// NewShippingCalculator builds the calculator selected by SHIPPING_STRATEGY.
+func NewShippingCalculator(cfg config.ShippingConfig) (ShippingCalculator, error) {
+	rate := domain.MoneyFromFloat(cfg.FlatRate, domain.DefaultCurrency)
+	switch cfg.Strategy {
+	case ShippingTypeFlat:
+		return FlatRateShipping{Rate: rate}, nil
+	case ShippingTypeFreeOverThreshold:
+		threshold := domain.MoneyFromFloat(cfg.FreeThreshold, domain.DefaultCurrency)
+		return FreeShippingOverThreshold{Rate: rate, Threshold: threshold}, nil
+	case ShippingTypeRules:
+		return LoadShippingRules(cfg.RulesFile)
+	default:
+		return nil, fmt.Errorf("unknown shipping strategy %q", cfg.Strategy)
+	}
+}
@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+func usd(cents int64) domain.Money {
+	return domain.NewMoney(cents, "USD")
+}
+
+func cartWith(items ...domain.CartItem) *domain.Cart {
+	cart := &domain.Cart{Currency: "USD", Items: items, Subtotal: usd(0)}
+	for _, item := range items {
+		cart.Subtotal, _ = cart.Subtotal.Add(item.ProductPrice.Mul(int64(item.Quantity)))
+	}
+	return cart
+}
+
+func TestShippingCalculators(t *testing.T) {
+	ctx := context.Background()
+
+	tiers, err := NewQuantityTierShipping([]ShippingTier{
+		{MinQuantity: 5, Rate: usd(200)},
+		{MinQuantity: 1, Rate: usd(600)},
+		{MinQuantity: 10, Rate: usd(0)},
+	})
+	if err != nil {
+		t.Fatalf("NewQuantityTierShipping() error = %v", err)
+	}
+
+	regional := &RegionalShipping{
+		Default: FlatRateShipping{Rate: usd(500)},
+		Regions: map[string]ShippingCalculator{"EU": FlatRateShipping{Rate: usd(900)}},
+	}
+
+	tests := []struct {
+		name   string
+		calc   ShippingCalculator
+		cart   *domain.Cart
+		region string
+		want   domain.Money
+	}{
+		{"Flat empty cart", FlatRateShipping{Rate: usd(500)}, cartWith(), "", usd(0)},
+		{"Flat", FlatRateShipping{Rate: usd(500)}, cartWith(domain.CartItem{ProductPrice: usd(1000), Quantity: 1}), "", usd(500)},
+		{"Below threshold", FreeShippingOverThreshold{Rate: usd(500), Threshold: usd(5000)}, cartWith(domain.CartItem{ProductPrice: usd(4999), Quantity: 1}), "", usd(500)},
+		{"At threshold", FreeShippingOverThreshold{Rate: usd(500), Threshold: usd(5000)}, cartWith(domain.CartItem{ProductPrice: usd(2500), Quantity: 2}), "", usd(0)},
+		{"First tier", tiers, cartWith(domain.CartItem{ProductPrice: usd(100), Quantity: 4}), "", usd(600)},
+		{"Middle tier", tiers, cartWith(domain.CartItem{ProductPrice: usd(100), Quantity: 3}, domain.CartItem{ProductPrice: usd(100), Quantity: 2}), "", usd(200)},
+		{"Top tier", tiers, cartWith(domain.CartItem{ProductPrice: usd(100), Quantity: 12}), "", usd(0)},
+		{"Known region", regional, cartWith(domain.CartItem{ProductPrice: usd(100), Quantity: 1}), "eu", usd(900)},
+		{"Unknown region", regional, cartWith(domain.CartItem{ProductPrice: usd(100), Quantity: 1}), "US", usd(500)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.calc.Calculate(ctx, tt.cart, tt.region)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Calculate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewQuantityTierShippingRequiresFirstUnit(t *testing.T) {
+	if _, err := NewQuantityTierShipping([]ShippingTier{{MinQuantity: 2, Rate: usd(100)}}); err == nil {
+		t.Fatal("expected error when lowest tier starts above 1")
+	}
+}
+
+func TestLoadShippingRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shipping.json")
+	rules := `{
+		"default": {"type": "flat", "rate": 5},
+		"regions": {"eu": {"type": "free_over_threshold", "rate": 7.5, "threshold": 100}}
+	}`
+	if err := os.WriteFile(path, []byte(rules), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	calc, err := LoadShippingRules(path)
+	if err != nil {
+		t.Fatalf("LoadShippingRules() error = %v", err)
+	}
+	if _, ok := calc.Regions["EU"]; !ok {
+		t.Fatalf("expected EU region to be loaded, got %v", calc.Regions)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"default": {"type": "teleport"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadShippingRules(bad); err == nil {
+		t.Fatal("expected error for unknown rule type")
+	}
+}
+
+func TestGetCartComputesTotals(t *testing.T) {
+	mockRepo := &MockCartRepository{
+		findByUserIDFunc: func(ctx context.Context, userID string) (*domain.Cart, error) {
+			return &domain.Cart{UserID: userID, Items: []domain.CartItem{
+				{ProductID: "p1", ProductPrice: usd(2999), Quantity: 2},
+				{ProductID: "p2", ProductPrice: usd(7999), Quantity: 1},
+			}}, nil
+		},
+	}
+	service := NewCartService(mockRepo, WithShippingCalculator(FreeShippingOverThreshold{Rate: usd(500), Threshold: usd(20000)}))
+
+	cart, err := service.GetCart(context.Background(), "user1", domain.GetCartRequest{})
+	if err != nil {
+		t.Fatalf("GetCart() error = %v", err)
+	}
+	if cart.ItemCount != 2 {
+		t.Errorf("ItemCount = %d, want 2", cart.ItemCount)
+	}
+	if cart.Subtotal != usd(13997) {
+		t.Errorf("Subtotal = %v, want 139.97", cart.Subtotal)
+	}
+	if cart.Shipping != usd(500) {
+		t.Errorf("Shipping = %v, want 5.00", cart.Shipping)
+	}
+	if cart.Total != usd(14497) {
+		t.Errorf("Total = %v, want 144.97", cart.Total)
+	}
+}
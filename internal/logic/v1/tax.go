@@ -0,0 +1,219 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+// DefaultTaxClass is used for items added without a tax class
+const DefaultTaxClass = "standard"
+
+// Jurisdiction identifies where tax is owed. State is the ISO 3166-2 subdivision
+// code without the country prefix (e.g. "CA" for US-CA) and may be empty.
+type Jurisdiction struct {
+	Country string
+	State   string
+}
+
+// ItemTax is the tax computed for a single cart item
+type ItemTax struct {
+	Rate   string       // Percentage, e.g. "7.25"
+	Amount domain.Money // Tax on the item's taxable amount
+}
+
+// TaxResult is the output of a TaxCalculator. Items is parallel to cart.Items.
+type TaxResult struct {
+	Items     []ItemTax
+	Total     domain.Money
+	Inclusive bool // Item prices already include Total
+}
+
+// TaxCalculator computes tax for a priced cart. The cart passed in already has
+// item subtotals, Subtotal and Discounts populated; tax is owed on the
+// discounted amount of each item.
+type TaxCalculator interface {
+	Calculate(ctx context.Context, cart *domain.Cart, j Jurisdiction) (TaxResult, error)
+}
+
+// NoTax is the calculator used when no tax rules are configured
+type NoTax struct{}
+
+// Calculate implements TaxCalculator.
+func (NoTax) Calculate(_ context.Context, cart *domain.Cart, _ Jurisdiction) (TaxResult, error) {
+	items := make([]ItemTax, len(cart.Items))
+	for i := range items {
+		items[i] = ItemTax{Rate: "0", Amount: domain.Zero(cart.Currency)}
+	}
+	return TaxResult{Items: items, Total: domain.Zero(cart.Currency)}, nil
+}
+
+// TaxTable looks up rates by jurisdiction and product tax class.
+// A "COUNTRY-STATE" entry takes precedence over the "COUNTRY" entry; unknown
+// jurisdictions and classes are not taxed. Tax is rounded half-even per item.
+type TaxTable struct {
+	inclusive bool
+	rates     map[string]map[string]*big.Rat // jurisdiction -> tax class -> rate (fraction)
+	display   map[string]map[string]string   // jurisdiction -> tax class -> percentage as configured
+}
+
+// TaxRules is the layout of the file referenced by TAX_RULES_FILE.
+// Rates are percentages given as decimal strings.
+//
+// Example:
+//
+//	{
+//	  "prices_include_tax": false,
+//	  "jurisdictions": {
+//	    "US-CA": {"standard": "7.25", "grocery": "0"},
+//	    "DE":    {"standard": "19", "reduced": "7"}
+//	  }
+//	}
+type TaxRules struct {
+	PricesIncludeTax bool                         `json:"prices_include_tax"`
+	Jurisdictions    map[string]map[string]string `json:"jurisdictions"`
+}
+
+// Table validates the rules and builds a TaxTable.
+func (r TaxRules) Table() (*TaxTable, error) {
+	t := &TaxTable{
+		inclusive: r.PricesIncludeTax,
+		rates:     make(map[string]map[string]*big.Rat, len(r.Jurisdictions)),
+		display:   make(map[string]map[string]string, len(r.Jurisdictions)),
+	}
+	for jurisdiction, classes := range r.Jurisdictions {
+		key := strings.ToUpper(jurisdiction)
+		t.rates[key] = make(map[string]*big.Rat, len(classes))
+		t.display[key] = make(map[string]string, len(classes))
+		for class, pct := range classes {
+			rate, ok := new(big.Rat).SetString(pct)
+			if !ok || rate.Sign() < 0 || rate.Cmp(big.NewRat(100, 1)) > 0 {
+				return nil, fmt.Errorf("jurisdiction %q class %q: invalid rate %q", jurisdiction, class, pct)
+			}
+			t.rates[key][strings.ToLower(class)] = rate.Quo(rate, big.NewRat(100, 1))
+			t.display[key][strings.ToLower(class)] = pct
+		}
+	}
+	return t, nil
+}
+
+// LoadTaxRules reads and validates a tax rules file.
+func LoadTaxRules(path string) (*TaxTable, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from operator config
+	if err != nil {
+		return nil, fmt.Errorf("read tax rules: %w", err)
+	}
+
+	var rules TaxRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse tax rules: %w", err)
+	}
+
+	return rules.Table()
+}
+
+// Calculate implements TaxCalculator.
+func (t *TaxTable) Calculate(_ context.Context, cart *domain.Cart, j Jurisdiction) (TaxResult, error) {
+	bases, err := taxableAmounts(cart)
+	if err != nil {
+		return TaxResult{}, err
+	}
+
+	result := TaxResult{
+		Items:     make([]ItemTax, len(cart.Items)),
+		Total:     domain.Zero(cart.Currency),
+		Inclusive: t.inclusive,
+	}
+	for i, item := range cart.Items {
+		rate, pct := t.lookup(j, item.TaxClass)
+		factor := rate
+		if t.inclusive {
+			// Extract the tax contained in a gross amount: gross * rate / (1 + rate)
+			factor = new(big.Rat).Quo(rate, new(big.Rat).Add(big.NewRat(1, 1), rate))
+		}
+		amount, err := bases[i].MulRat(factor, domain.RoundHalfEven)
+		if err != nil {
+			return TaxResult{}, err
+		}
+		result.Items[i] = ItemTax{Rate: pct, Amount: amount}
+		if result.Total, err = result.Total.Add(amount); err != nil {
+			return TaxResult{}, err
+		}
+	}
+	return result, nil
+}
+
+// lookup returns the rate for the item's tax class in the most specific matching jurisdiction
+func (t *TaxTable) lookup(j Jurisdiction, class string) (*big.Rat, string) {
+	if class == "" {
+		class = DefaultTaxClass
+	}
+	class = strings.ToLower(class)
+
+	keys := []string{strings.ToUpper(j.Country)}
+	if j.State != "" {
+		keys = append([]string{strings.ToUpper(j.Country + "-" + j.State)}, keys...)
+	}
+	for _, key := range keys {
+		if rate, ok := t.rates[key][class]; ok {
+			return rate, t.display[key][class]
+		}
+	}
+	return new(big.Rat), "0"
+}
+
+// taxableAmounts returns each item's subtotal net of its item-level discounts and
+// its share of order-level discounts, allocated in proportion to the net amounts.
+// Rounding remainders go to the last item so the shares add up exactly.
+func taxableAmounts(cart *domain.Cart) ([]domain.Money, error) {
+	bases := make([]domain.Money, len(cart.Items))
+	index := make(map[string]int, len(cart.Items))
+	for i, item := range cart.Items {
+		bases[i] = item.Subtotal
+		index[item.ID] = i
+	}
+
+	orderDiscount := domain.Zero(cart.Currency)
+	for _, d := range cart.Discounts {
+		var err error
+		if d.Scope == domain.DiscountScopeItem {
+			i := index[d.ItemID]
+			bases[i], err = bases[i].Sub(d.Amount)
+		} else {
+			orderDiscount, err = orderDiscount.Add(d.Amount)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if orderDiscount.IsZero() {
+		return bases, nil
+	}
+
+	var net int64
+	for _, b := range bases {
+		net += b.Amount
+	}
+	if net <= 0 {
+		return bases, nil
+	}
+
+	allocated := int64(0)
+	last := len(bases) - 1
+	for i := range bases {
+		share := orderDiscount.Amount - allocated
+		if i != last {
+			share = new(big.Int).Div(
+				new(big.Int).Mul(big.NewInt(orderDiscount.Amount), big.NewInt(bases[i].Amount)),
+				big.NewInt(net)).Int64()
+		}
+		allocated += share
+		bases[i] = domain.NewMoney(bases[i].Amount-share, bases[i].Currency)
+	}
+	return bases, nil
+}
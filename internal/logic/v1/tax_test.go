@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+func testTaxTable(t *testing.T, inclusive bool) *TaxTable {
+	t.Helper()
+	table, err := TaxRules{
+		PricesIncludeTax: inclusive,
+		Jurisdictions: map[string]map[string]string{
+			"US-CA": {"standard": "7.25", "grocery": "0"},
+			"DE":    {"standard": "19", "reduced": "7"},
+		},
+	}.Table()
+	if err != nil {
+		t.Fatalf("Table() error = %v", err)
+	}
+	return table
+}
+
+func TestTaxTable(t *testing.T) {
+	book := domain.CartItem{ID: "1", TaxClass: "reduced", ProductPrice: usd(1000), Quantity: 1}
+	lamp := domain.CartItem{ID: "2", ProductPrice: usd(11900), Quantity: 1}
+
+	tests := []struct {
+		name      string
+		inclusive bool
+		j         Jurisdiction
+		want      []domain.Money
+	}{
+		{"Exclusive by class", false, Jurisdiction{Country: "de"}, []domain.Money{usd(70), usd(2261)}},
+		{"Inclusive extracts tax", true, Jurisdiction{Country: "DE"}, []domain.Money{usd(65), usd(1900)}},
+		{"State overrides country", false, Jurisdiction{Country: "US", State: "CA"}, []domain.Money{usd(0), usd(863)}},
+		{"Unknown jurisdiction", false, Jurisdiction{Country: "FR"}, []domain.Money{usd(0), usd(0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := testTaxTable(t, tt.inclusive).Calculate(context.Background(), pricedCart(book, lamp), tt.j)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			for i, want := range tt.want {
+				if result.Items[i].Amount != want {
+					t.Errorf("item %d tax = %v, want %v", i, result.Items[i].Amount, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTaxAllocatesOrderDiscounts(t *testing.T) {
+	cart := pricedCart(
+		domain.CartItem{ID: "1", ProductPrice: usd(3000), Quantity: 1},
+		domain.CartItem{ID: "2", ProductPrice: usd(1000), Quantity: 1},
+	)
+	cart.Discounts = []domain.AppliedDiscount{{Scope: domain.DiscountScopeOrder, Amount: usd(1000)}}
+
+	bases, err := taxableAmounts(cart)
+	if err != nil {
+		t.Fatalf("taxableAmounts() error = %v", err)
+	}
+	if bases[0] != usd(2250) || bases[1] != usd(750) {
+		t.Errorf("taxable amounts = %v, %v, want 22.50, 7.50", bases[0], bases[1])
+	}
+}
+
+func TestGetCartAddsExclusiveTax(t *testing.T) {
+	mockRepo := &MockCartRepository{
+		findByUserIDFunc: func(ctx context.Context, userID string) (*domain.Cart, error) {
+			return &domain.Cart{UserID: userID, Currency: "USD", Items: []domain.CartItem{
+				{ID: "1", ProductPrice: usd(10000), Quantity: 1},
+			}}, nil
+		},
+	}
+	service := NewCartService(mockRepo, WithTaxCalculator(testTaxTable(t, false)))
+
+	cart, err := service.GetCart(context.Background(), "user1", domain.GetCartRequest{Country: "DE"})
+	if err != nil {
+		t.Fatalf("GetCart() error = %v", err)
+	}
+	if cart.Tax != usd(1900) || cart.Items[0].TaxRate != "19" {
+		t.Errorf("Tax = %v at %s%%, want 19.00 at 19%%", cart.Tax, cart.Items[0].TaxRate)
+	}
+	// 100.00 + 5.00 shipping + 19.00 tax
+	if cart.Total != usd(12400) {
+		t.Errorf("Total = %v, want 124.00", cart.Total)
+	}
+}
@@ -0,0 +1,315 @@
+package v1
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/cart-service/middleware"
+	"github.com/duynhne/pkg/logger/clog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	dispatchBatchSize = 50
+	// dispatchConcurrency bounds the requests in flight per dispatcher, so one
+	// slow endpoint does not hold back the others
+	dispatchConcurrency = 8
+	// dispatchLease is how long claimed deliveries stay reserved for one dispatcher
+	dispatchLease = time.Minute
+	// Failed deliveries back off exponentially from webhookMinBackoff up to webhookMaxBackoff
+	webhookMinBackoff = 30 * time.Second
+	webhookMaxBackoff = 6 * time.Hour
+	// maxDeliveryLogLimit bounds a page of the delivery log
+	maxDeliveryLogLimit = 200
+)
+
+var webhookDeliveries = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "webhook_deliveries_total",
+		Help: "Webhook delivery attempts by result",
+	},
+	[]string{"result"}, // delivered, failed, dead
+)
+
+// WebhookSender sends one signed webhook delivery and returns the HTTP status
+type WebhookSender interface {
+	Send(ctx context.Context, delivery domain.WebhookDelivery) (int, error)
+}
+
+// WebhookService manages webhook subscriptions. It is also the EventPublisher
+// that fans cart events from the outbox out to matching subscriptions, where
+// WebhookDispatcher picks them up.
+type WebhookService struct {
+	repo domain.WebhookRepository
+}
+
+// NewWebhookService creates a webhook service
+func NewWebhookService(repo domain.WebhookRepository) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+// CreateSubscription registers an active subscription. The returned
+// subscription carries the signing secret, which is not shown again.
+func (s *WebhookService) CreateSubscription(ctx context.Context, req domain.CreateWebhookRequest) (*domain.WebhookSubscription, error) {
+	ctx, span := middleware.StartSpan(ctx, "webhook.create", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	eventTypes := make([]domain.EventType, 0, len(req.EventTypes))
+	for _, t := range req.EventTypes {
+		if !slices.Contains(domain.EventTypes, t) {
+			return nil, fmt.Errorf("event type %q: %w", t, ErrInvalidEventType)
+		}
+		if !slices.Contains(eventTypes, t) {
+			eventTypes = append(eventTypes, t)
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generate webhook secret: %w", err)
+		}
+		secret = "whsec_" + hex.EncodeToString(key)
+	}
+
+	sub := &domain.WebhookSubscription{
+		URL:         req.URL,
+		EventTypes:  eventTypes,
+		Secret:      secret,
+		Description: req.Description,
+		Active:      true,
+	}
+
+	// Call repository
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("create webhook subscription: %w", err)
+	}
+
+	span.SetAttributes(attribute.String("webhook.id", sub.ID))
+	return sub, nil
+}
+
+// ListSubscriptions returns every subscription, without secrets
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	ctx, span := middleware.StartSpan(ctx, "webhook.list", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+	))
+	defer span.End()
+
+	// Call repository
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("list webhook subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// DeleteSubscription removes a subscription and its delivery log
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	ctx, span := middleware.StartSpan(ctx, "webhook.delete", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("webhook.id", id),
+	))
+	defer span.End()
+
+	// Call repository
+	if err := s.repo.DeleteSubscription(ctx, id); err != nil {
+		span.RecordError(err)
+		return webhookError(fmt.Sprintf("delete webhook subscription %s", id), err)
+	}
+	return nil
+}
+
+// ListDeliveries returns up to limit of the latest deliveries of a subscription,
+// optionally only those with status
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID string, status domain.DeliveryStatus, limit int) ([]domain.WebhookDelivery, error) {
+	ctx, span := middleware.StartSpan(ctx, "webhook.deliveries", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("webhook.id", subscriptionID),
+	))
+	defer span.End()
+
+	if limit <= 0 || limit > maxDeliveryLogLimit {
+		limit = maxDeliveryLogLimit
+	}
+
+	// Call repository
+	deliveries, err := s.repo.ListDeliveries(ctx, subscriptionID, status, limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, webhookError(fmt.Sprintf("list deliveries of webhook %s", subscriptionID), err)
+	}
+	return deliveries, nil
+}
+
+// Redeliver schedules a dead or delivered delivery to be sent again
+func (s *WebhookService) Redeliver(ctx context.Context, subscriptionID, deliveryID string) error {
+	ctx, span := middleware.StartSpan(ctx, "webhook.redeliver", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.String("webhook.id", subscriptionID),
+		attribute.String("delivery.id", deliveryID),
+	))
+	defer span.End()
+
+	// Call repository
+	if err := s.repo.Redeliver(ctx, subscriptionID, deliveryID); err != nil {
+		span.RecordError(err)
+		return webhookError(fmt.Sprintf("redeliver webhook delivery %s", deliveryID), err)
+	}
+	return nil
+}
+
+// Publish implements domain.EventPublisher by enqueueing a delivery of the
+// event for every matching subscription
+func (s *WebhookService) Publish(ctx context.Context, event domain.Event) error {
+	if _, err := s.repo.Enqueue(ctx, event); err != nil {
+		return fmt.Errorf("enqueue webhook deliveries for event %s: %w", event.ID, err)
+	}
+	return nil
+}
+
+// webhookError maps repository not-found errors to ErrWebhookNotFound
+func webhookError(op string, err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("%s: %w", op, ErrWebhookNotFound)
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// WebhookDispatcher sends pending webhook deliveries. A delivery that fails is
+// retried with exponential backoff and dead-lettered after maxAttempts; dead
+// deliveries stay in the delivery log until an admin redelivers them. Several
+// replicas may dispatch at once, each claiming different deliveries.
+type WebhookDispatcher struct {
+	repo        domain.WebhookRepository
+	sender      WebhookSender
+	maxAttempts int
+	interval    time.Duration
+}
+
+// NewWebhookDispatcher creates a dispatcher that polls for due deliveries every interval
+func NewWebhookDispatcher(repo domain.WebhookRepository, sender WebhookSender, maxAttempts int, interval time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{repo: repo, sender: sender, maxAttempts: maxAttempts, interval: interval}
+}
+
+// Run dispatches deliveries every interval until ctx is cancelled
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Dispatch(ctx); err != nil && ctx.Err() == nil {
+				clog.WarnContext(ctx, "Webhook dispatch failed", "error", err)
+			}
+		}
+	}
+}
+
+// Dispatch sends due deliveries in batches until none are left
+func (d *WebhookDispatcher) Dispatch(ctx context.Context) error {
+	for {
+		deliveries, err := d.repo.ClaimDeliveries(ctx, dispatchBatchSize, dispatchLease)
+		if err != nil {
+			return err
+		}
+		if err := d.sendBatch(ctx, deliveries); err != nil || len(deliveries) < dispatchBatchSize {
+			return err
+		}
+	}
+}
+
+// sendBatch sends claimed deliveries concurrently and records each outcome
+func (d *WebhookDispatcher) sendBatch(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	ctx, span := middleware.StartSpan(ctx, "webhook.dispatch", trace.WithAttributes(
+		attribute.String("layer", "logic"),
+		attribute.Int("deliveries.claimed", len(deliveries)),
+	))
+	defer span.End()
+
+	sendCtx, cancel := context.WithTimeout(ctx, dispatchLease/2)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	slots := make(chan struct{}, dispatchConcurrency)
+	for _, delivery := range deliveries {
+		slots <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			result := d.attempt(sendCtx, delivery)
+			if err := d.repo.RecordResult(ctx, delivery.ID, result); err != nil {
+				mu.Lock()
+				firstErr = cmp.Or(firstErr, err)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		span.RecordError(firstErr)
+	}
+	return firstErr
+}
+
+// attempt sends one delivery and decides what happens to it next
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery domain.WebhookDelivery) domain.DeliveryResult {
+	code, err := d.sender.Send(ctx, delivery)
+	if err == nil {
+		webhookDeliveries.WithLabelValues("delivered").Inc()
+		return domain.DeliveryResult{Status: domain.DeliveryDelivered, StatusCode: code}
+	}
+
+	result := domain.DeliveryResult{StatusCode: code, Error: err.Error()}
+	if delivery.Attempts >= d.maxAttempts {
+		webhookDeliveries.WithLabelValues("dead").Inc()
+		result.Status = domain.DeliveryDead
+		clog.WarnContext(ctx, "Webhook delivery dead-lettered",
+			"delivery_id", delivery.ID, "subscription_id", delivery.SubscriptionID,
+			"event_id", delivery.EventID, "attempts", delivery.Attempts, "error", err)
+		return result
+	}
+
+	webhookDeliveries.WithLabelValues("failed").Inc()
+	result.Status = domain.DeliveryPending
+	result.RetryAt = time.Now().Add(webhookBackoff(delivery.Attempts))
+	clog.DebugContext(ctx, "Webhook delivery failed",
+		"delivery_id", delivery.ID, "subscription_id", delivery.SubscriptionID,
+		"attempts", delivery.Attempts, "error", err)
+	return result
+}
+
+// webhookBackoff returns the delay before the next delivery attempt after attempts failures
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookMinBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
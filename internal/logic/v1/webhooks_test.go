@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+)
+
+// memoryWebhooks is an in-memory domain.WebhookRepository
+type memoryWebhooks struct {
+	mu         sync.Mutex
+	subs       []domain.WebhookSubscription
+	deliveries []domain.WebhookDelivery
+	results    map[string]domain.DeliveryResult
+}
+
+func newMemoryWebhooks() *memoryWebhooks {
+	return &memoryWebhooks{results: map[string]domain.DeliveryResult{}}
+}
+
+func (m *memoryWebhooks) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	sub.ID = strconv.Itoa(len(m.subs) + 1)
+	m.subs = append(m.subs, *sub)
+	return nil
+}
+
+func (m *memoryWebhooks) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return m.subs, nil
+}
+
+func (m *memoryWebhooks) DeleteSubscription(ctx context.Context, id string) error {
+	return domain.ErrNotFound
+}
+
+func (m *memoryWebhooks) Enqueue(ctx context.Context, event domain.Event) (int, error) {
+	n := 0
+	for _, sub := range m.subs {
+		if sub.Active && sub.Matches(event.Type) {
+			m.deliveries = append(m.deliveries, domain.WebhookDelivery{
+				ID:             strconv.Itoa(len(m.deliveries) + 1),
+				SubscriptionID: sub.ID,
+				EventID:        event.ID,
+				EventType:      event.Type,
+				Status:         domain.DeliveryPending,
+				URL:            sub.URL,
+				Secret:         sub.Secret,
+			})
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memoryWebhooks) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	var claimed []domain.WebhookDelivery
+	for i := range m.deliveries {
+		d := &m.deliveries[i]
+		if len(claimed) == limit || d.Status != domain.DeliveryPending || (d.NextAttemptAt != nil && d.NextAttemptAt.After(time.Now())) {
+			continue
+		}
+		d.Attempts++
+		next := time.Now().Add(lease)
+		d.NextAttemptAt = &next
+		claimed = append(claimed, *d)
+	}
+	return claimed, nil
+}
+
+func (m *memoryWebhooks) RecordResult(ctx context.Context, id string, result domain.DeliveryResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.deliveries {
+		if d := &m.deliveries[i]; d.ID == id {
+			d.Status = result.Status
+			d.NextAttemptAt = &result.RetryAt
+			m.results[id] = result
+		}
+	}
+	return nil
+}
+
+func (m *memoryWebhooks) ListDeliveries(ctx context.Context, subscriptionID string, status domain.DeliveryStatus, limit int) ([]domain.WebhookDelivery, error) {
+	return nil, domain.ErrNotFound
+}
+
+func (m *memoryWebhooks) Redeliver(ctx context.Context, subscriptionID, deliveryID string) error {
+	return domain.ErrNotFound
+}
+
+// failingSender fails every delivery to one URL
+type failingSender struct {
+	mu      sync.Mutex
+	failURL string
+	sent    int
+}
+
+func (s *failingSender) Send(ctx context.Context, d domain.WebhookDelivery) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d.URL == s.failURL {
+		return 503, errors.New("webhook returned 503")
+	}
+	s.sent++
+	return 200, nil
+}
+
+func TestCreateWebhookSubscription(t *testing.T) {
+	ctx := context.Background()
+	service := NewWebhookService(newMemoryWebhooks())
+
+	sub, err := service.CreateSubscription(ctx, domain.CreateWebhookRequest{
+		URL:        "https://hooks.example.com/cart",
+		EventTypes: []domain.EventType{domain.EventCartCleared, domain.EventCartCleared},
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+	if len(sub.Secret) < 32 || !sub.Active || len(sub.EventTypes) != 1 {
+		t.Errorf("subscription = %+v, want active with a generated secret and deduplicated event types", sub)
+	}
+
+	_, err = service.CreateSubscription(ctx, domain.CreateWebhookRequest{
+		URL:        "https://hooks.example.com/cart",
+		EventTypes: []domain.EventType{"cart.shipped"},
+	})
+	if !errors.Is(err, ErrInvalidEventType) {
+		t.Errorf("unknown event type error = %v, want ErrInvalidEventType", err)
+	}
+
+	if err := service.DeleteSubscription(ctx, "99"); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("DeleteSubscription() of a missing subscription error = %v, want ErrWebhookNotFound", err)
+	}
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryWebhooks()
+	service := NewWebhookService(repo)
+	for _, url := range []string{"https://ok.example.com", "https://down.example.com"} {
+		if _, err := service.CreateSubscription(ctx, domain.CreateWebhookRequest{URL: url}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := service.CreateSubscription(ctx, domain.CreateWebhookRequest{
+		URL:        "https://removals.example.com",
+		EventTypes: []domain.EventType{domain.EventCartItemRemoved},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The filtered subscription does not get the event
+	if err := service.Publish(ctx, domain.Event{ID: "1", Type: domain.EventCartItemAdded}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if len(repo.deliveries) != 2 {
+		t.Fatalf("enqueued %d deliveries, want 2", len(repo.deliveries))
+	}
+
+	sender := &failingSender{failURL: "https://down.example.com"}
+	dispatcher := NewWebhookDispatcher(repo, sender, 2, time.Second)
+
+	before := time.Now()
+	if err := dispatcher.Dispatch(ctx); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	if repo.deliveries[0].Status != domain.DeliveryDelivered || sender.sent != 1 {
+		t.Errorf("healthy delivery status = %s, want delivered", repo.deliveries[0].Status)
+	}
+	failed := repo.results[repo.deliveries[1].ID]
+	if failed.Status != domain.DeliveryPending || failed.StatusCode != 503 || failed.RetryAt.Before(before.Add(webhookMinBackoff)) {
+		t.Errorf("failed delivery result = %+v, want pending with a backoff", failed)
+	}
+
+	// The last allowed attempt dead-letters the delivery
+	repo.deliveries[1].NextAttemptAt = nil
+	if err := dispatcher.Dispatch(ctx); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	if repo.deliveries[1].Status != domain.DeliveryDead || repo.deliveries[1].Attempts != 2 {
+		t.Errorf("delivery status = %s after %d attempts, want dead after 2", repo.deliveries[1].Status, repo.deliveries[1].Attempts)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, webhookMinBackoff},
+		{3, 4 * webhookMinBackoff},
+		{50, webhookMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
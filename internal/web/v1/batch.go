@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/cart-service/middleware"
+	"github.com/duynhne/pkg/logger/clog"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// batchResponse is the body of POST /cart/items:batch
+type batchResponse struct {
+	Mode    domain.BatchMode  `json:"mode"`
+	Applied bool              `json:"applied"` // Whether any operation changed the cart
+	Results []batchOpResponse `json:"results"`
+	Cart    *domain.Cart      `json:"cart"`
+}
+
+// batchOpResponse is the outcome of one operation, at the operation's index in the request
+type batchOpResponse struct {
+	Index  int                  `json:"index"`
+	Op     domain.BatchOpType   `json:"op"`
+	Status domain.BatchOpStatus `json:"status"`
+	Item   *domain.CartItem     `json:"item,omitempty"`
+	Error  *batchOpError        `json:"error,omitempty"`
+}
+
+// batchOpError reports a failed operation with the code and message the
+// single-item endpoint would have answered
+type batchOpError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BatchItems handles POST /cart/items:batch. Answers 200 when the batch was
+// applied, even partly in best-effort mode, and 422 when an atomic batch was
+// rolled back; the results say which operations failed and why.
+func (h *CartHandler) BatchItems(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.BatchItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Invalid request", "error", err)
+		respondBindError(c, err, &req)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = domain.BatchAtomic
+	}
+
+	batch, err := h.cartService.BatchItems(ctx, userID, req)
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to apply cart batch", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	resp := batchResponse{Mode: req.Mode, Applied: batch.Applied, Cart: batch.Cart}
+	failed := 0
+	for i, result := range batch.Results {
+		op := batchOpResponse{Index: i, Op: result.Op, Status: result.Status, Item: result.Item}
+		if result.Err != nil {
+			failed++
+			api := mapError(result.Err)
+			op.Error = &batchOpError{Code: api.Code, Message: api.Message}
+		}
+		resp.Results = append(resp.Results, op)
+	}
+
+	clog.InfoContext(ctx, "Cart batch applied", "user_id", userID, "mode", req.Mode,
+		"operations", len(batch.Results), "failed", failed)
+	setCartETag(c, batch.Cart)
+	status := http.StatusOK
+	if req.Mode == domain.BatchAtomic && failed > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, resp)
+}
@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	logicv1 "github.com/duynhne/cart-service/internal/logic/v1"
+	"github.com/duynhne/cart-service/middleware"
+	"github.com/duynhne/pkg/logger/clog"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SelectCart scopes the request to the cart named by the :cartId route parameter,
+// so the /cart handlers serve /carts/:cartId routes. Without it they act on the
+// user's active cart.
+func SelectCart(c *gin.Context) {
+	cartID := c.Param("cartId")
+	if _, err := strconv.ParseInt(cartID, 10, 64); err != nil {
+		respondError(c, logicv1.ErrCartNotFound)
+		return
+	}
+	c.Request = c.Request.WithContext(domain.WithCart(c.Request.Context(), cartID))
+	c.Next()
+}
+
+// ListCarts handles GET /carts
+func (h *CartHandler) ListCarts(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	carts, err := h.cartService.ListCarts(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to list carts", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"carts": carts})
+}
+
+// CreateCart handles POST /carts
+func (h *CartHandler) CreateCart(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Invalid request", "error", err)
+		respondBindError(c, err, &req)
+		return
+	}
+
+	cart, err := h.cartService.CreateCart(ctx, userID, req)
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to create cart", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	clog.InfoContext(ctx, "Cart created", "user_id", userID, "cart_id", cart.ID, "kind", cart.Kind)
+	c.Header("Location", c.FullPath()+"/"+cart.ID)
+	c.JSON(http.StatusCreated, cart)
+}
+
+// DeleteCart handles DELETE /carts/:cartId
+func (h *CartHandler) DeleteCart(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.cartService.DeleteCart(ctx, userID, c.Param("cartId")); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to delete cart", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cart deleted"})
+}
+
+// ActivateCart handles POST /carts/:cartId/activate
+func (h *CartHandler) ActivateCart(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.cartService.ActivateCart(ctx, userID, c.Param("cartId")); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to activate cart", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cart activated"})
+}
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	logicv1 "github.com/duynhne/cart-service/internal/logic/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSelectCart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	selected := func(cartID string) any {
+		return mock.MatchedBy(func(ctx context.Context) bool { return domain.SelectedCart(ctx) == cartID })
+	}
+
+	mockRepo := new(MockCartRepository)
+	mockRepo.On("FindByUserID", selected(""), "1").Return(&domain.Cart{ID: "1", UserID: "1", Active: true}, nil)
+	mockRepo.On("FindByUserID", selected("7"), "1").Return(&domain.Cart{ID: "7", UserID: "1", Name: "office"}, nil)
+	mockRepo.On("FindByUserID", selected("8"), "1").Return(nil, domain.ErrCartNotFound)
+
+	handler := NewCartHandler(logicv1.NewCartService(mockRepo))
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", "1") })
+	r.GET("/cart", handler.GetCart)
+	r.GET("/carts/:cartId", SelectCart, handler.GetCart)
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"/cart", http.StatusOK, `"id":"1"`},
+		{"/carts/7", http.StatusOK, `"name":"office"`},
+		{"/carts/8", http.StatusNotFound, `"code":"CART_NOT_FOUND"`},
+		{"/carts/abc", http.StatusNotFound, `"code":"CART_NOT_FOUND"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+}
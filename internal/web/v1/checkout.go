@@ -0,0 +1,144 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	logicv1 "github.com/duynhne/cart-service/internal/logic/v1"
+	"github.com/duynhne/cart-service/middleware"
+	"github.com/duynhne/pkg/logger/clog"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// snapshotIDParam returns the :snapshotId route parameter, answering 404 if it is not a valid ID
+func snapshotIDParam(c *gin.Context) (string, bool) {
+	id := c.Param("snapshotId")
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		respondError(c, logicv1.ErrSnapshotNotFound)
+		return "", false
+	}
+	return id, true
+}
+
+// Checkout handles POST /cart/checkout: freezes the cart into a snapshot for the
+// order service and locks it until the order is confirmed or cancelled
+func (h *CartHandler) Checkout(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Invalid request", "error", err)
+		respondBindError(c, err, &req)
+		return
+	}
+
+	snapshot, err := h.cartService.Checkout(ctx, userID, req)
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to check out cart", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	clog.InfoContext(ctx, "Cart locked for checkout", "user_id", userID, "snapshot_id", snapshot.ID)
+	c.JSON(http.StatusCreated, snapshot)
+}
+
+// GetCheckout handles GET /internal/checkouts/:snapshotId
+func (h *CartHandler) GetCheckout(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	id, ok := snapshotIDParam(c)
+	if !ok {
+		return
+	}
+
+	snapshot, err := h.cartService.GetSnapshot(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to get checkout snapshot", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// ConfirmCheckout handles POST /internal/checkouts/:snapshotId/confirm
+func (h *CartHandler) ConfirmCheckout(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	id, ok := snapshotIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ConfirmCheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Invalid request", "error", err)
+		respondBindError(c, err, &req)
+		return
+	}
+
+	if err := h.cartService.ConfirmCheckout(ctx, id, req.OrderID); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to confirm checkout", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	clog.InfoContext(ctx, "Checkout confirmed", "snapshot_id", id, "order_id", req.OrderID)
+	c.Status(http.StatusNoContent)
+}
+
+// CancelCheckout handles POST /internal/checkouts/:snapshotId/cancel
+func (h *CartHandler) CancelCheckout(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	id, ok := snapshotIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.cartService.CancelCheckout(ctx, id); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to cancel checkout", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	clog.InfoContext(ctx, "Checkout cancelled", "snapshot_id", id)
+	c.Status(http.StatusNoContent)
+}
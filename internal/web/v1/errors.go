@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	logicv1 "github.com/duynhne/cart-service/internal/logic/v1"
+	"github.com/duynhne/cart-service/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// apiError is how an error is reported to clients: HTTP status, a stable
+// machine-readable code and a message that is safe to expose.
+type apiError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+// Stable codes for errors that do not come from a sentinel
+const (
+	codeInvalidRequest = "INVALID_REQUEST"
+	codeInternal       = middleware.CodeInternal
+)
+
+var errInternal = apiError{http.StatusInternalServerError, codeInternal, "Internal server error"}
+
+// errorMappings maps sentinel errors to API errors. Checked in order with errors.Is,
+// so logic-layer sentinels come before the generic domain errors they may wrap.
+var errorMappings = []struct {
+	err error
+	api apiError
+}{
+	{logicv1.ErrCartNotFound, apiError{http.StatusNotFound, "CART_NOT_FOUND", "Cart not found"}},
+	{logicv1.ErrCartEmpty, apiError{http.StatusBadRequest, "CART_EMPTY", "Cart is empty"}},
+	{logicv1.ErrItemNotInCart, apiError{http.StatusNotFound, "ITEM_NOT_IN_CART", "Item not in cart"}},
+	{logicv1.ErrCartModified, apiError{http.StatusPreconditionFailed, "CART_VERSION_MISMATCH", "Cart was modified since the If-Match version; fetch it again and retry"}},
+	{logicv1.ErrCartItemNotFound, apiError{http.StatusNotFound, "CART_ITEM_NOT_FOUND", "Cart item not found"}},
+	{logicv1.ErrSavedItemNotFound, apiError{http.StatusNotFound, "SAVED_ITEM_NOT_FOUND", "Saved item not found"}},
+	{logicv1.ErrInvalidQuantity, apiError{http.StatusBadRequest, "INVALID_QUANTITY", "Quantity must be at least 1"}},
+	{logicv1.ErrInvalidPrice, apiError{http.StatusBadRequest, "INVALID_PRICE", "Product price must be positive"}},
+	{logicv1.ErrInvalidProductName, apiError{http.StatusBadRequest, "INVALID_PRODUCT_NAME", "Product name is required"}},
+	{logicv1.ErrProductNotFound, apiError{http.StatusNotFound, "PRODUCT_NOT_FOUND", "Product not found"}},
+	{logicv1.ErrProductUnavailable, apiError{http.StatusConflict, "PRODUCT_UNAVAILABLE", "Product is not available"}},
+	{logicv1.ErrCatalogUnavailable, apiError{http.StatusServiceUnavailable, "CATALOG_UNAVAILABLE", "Product catalog is temporarily unavailable"}},
+	{logicv1.ErrMixedCurrency, apiError{http.StatusConflict, "CURRENCY_MISMATCH", "Item currency does not match the cart currency"}},
+	{logicv1.ErrUnsupportedCurrency, apiError{http.StatusBadRequest, "UNSUPPORTED_CURRENCY", "Currency is not supported"}},
+	{logicv1.ErrCouponNotFound, apiError{http.StatusNotFound, "COUPON_NOT_FOUND", "Coupon not found"}},
+	{logicv1.ErrCouponNotApplicable, apiError{http.StatusUnprocessableEntity, "COUPON_NOT_APPLICABLE", "Coupon does not apply to this cart"}},
+	{logicv1.ErrCouponUsageLimitReached, apiError{http.StatusConflict, "COUPON_USAGE_LIMIT_REACHED", "Coupon usage limit reached"}},
+	{logicv1.ErrNoGuestCart, apiError{http.StatusBadRequest, "NO_GUEST_CART", "No guest cart to merge"}},
+	{logicv1.ErrInsufficientStock, apiError{http.StatusBadRequest, "INSUFFICIENT_STOCK", "Insufficient stock for the requested quantity"}},
+	{logicv1.ErrWebhookNotFound, apiError{http.StatusNotFound, "WEBHOOK_NOT_FOUND", "Webhook subscription or delivery not found"}},
+	{logicv1.ErrInvalidEventType, apiError{http.StatusBadRequest, "INVALID_EVENT_TYPE", "Unknown event type"}},
+	{logicv1.ErrBatchTooLarge, apiError{http.StatusBadRequest, "BATCH_TOO_LARGE", "Batch has too many operations"}},
+	{logicv1.ErrSnapshotNotFound, apiError{http.StatusNotFound, "SNAPSHOT_NOT_FOUND", "Checkout snapshot not found"}},
+	{logicv1.ErrCheckoutClosed, apiError{http.StatusConflict, "CHECKOUT_CLOSED", "Checkout was already resolved or has expired"}},
+	{logicv1.ErrUnauthorized, apiError{http.StatusForbidden, "FORBIDDEN", "Not allowed to access this cart"}},
+
+	{domain.ErrNotFound, apiError{http.StatusNotFound, "NOT_FOUND", "Resource not found"}},
+	{domain.ErrInvalidInput, apiError{http.StatusBadRequest, "INVALID_INPUT", "Invalid input"}},
+	{domain.ErrCurrencyMismatch, apiError{http.StatusConflict, "CURRENCY_MISMATCH", "Item currency does not match the cart currency"}},
+	{domain.ErrCartNotFound, apiError{http.StatusNotFound, "CART_NOT_FOUND", "Cart not found"}},
+	{domain.ErrVersionMismatch, apiError{http.StatusPreconditionFailed, "CART_VERSION_MISMATCH", "Cart was modified since the If-Match version; fetch it again and retry"}},
+	{domain.ErrCartFull, apiError{http.StatusConflict, "CART_FULL", "Cart holds the maximum number of lines"}},
+	{domain.ErrCartLocked, apiError{http.StatusConflict, "CART_LOCKED", "Cart is locked while an order is placed from it"}},
+	{domain.ErrConflict, apiError{http.StatusConflict, "CONFLICT", "Request conflicts with the current cart state"}},
+}
+
+// mapError returns the API error for err; unknown errors are 500 INTERNAL_ERROR
+func mapError(err error) apiError {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m.api
+		}
+	}
+	return errInternal
+}
+
+// respondError writes the mapped error as a problem details response and aborts the request
+func respondError(c *gin.Context, err error) {
+	api := mapError(err)
+	middleware.WriteProblem(c, middleware.Problem{
+		Status: api.Status,
+		Code:   api.Code,
+		Detail: api.Message,
+	})
+}
+
+// respondBindError reports a request that failed binding into obj. Validation
+// failures are listed per field in invalid_params under their JSON or query names;
+// raw decoder and validator messages are not exposed.
+func respondBindError(c *gin.Context, err error, obj any) {
+	problem := middleware.Problem{
+		Status: http.StatusBadRequest,
+		Code:   codeInvalidRequest,
+		Detail: "Request validation failed",
+	}
+
+	var validationErrs validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fe := range validationErrs {
+			problem.InvalidParams = append(problem.InvalidParams, middleware.InvalidParam{
+				Name:   paramName(obj, fe.StructField()),
+				Reason: validationReason(fe),
+			})
+		}
+	case errors.As(err, &typeErr):
+		problem.InvalidParams = []middleware.InvalidParam{{
+			Name:   typeErr.Field,
+			Reason: "must be of type " + typeErr.Type.String(),
+		}}
+	case errors.Is(err, domain.ErrInvalidMoney):
+		problem.Detail = "Money amounts must be decimals with no more digits than the currency's minor unit"
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		problem.Detail = "Request body is not valid JSON"
+	default:
+		problem.Detail = "Request body could not be parsed"
+	}
+
+	middleware.WriteProblem(c, problem)
+}
+
+// paramName returns the json (or form) tag name of field in obj's struct type
+func paramName(obj any, field string) string {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return field
+	}
+	f, ok := t.FieldByName(field)
+	if !ok {
+		return field
+	}
+	for _, key := range []string{"json", "form"} {
+		if name, _, _ := strings.Cut(f.Tag.Get(key), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return field
+}
+
+// validationReason renders a validator failure as a short, client-facing sentence
+func validationReason(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
+	case "iso3166_1_alpha2":
+		return "must be an ISO 3166-1 alpha-2 country code"
+	default:
+		return "failed the " + fe.Tag() + " check"
+	}
+}
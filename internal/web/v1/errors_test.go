@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	logicv1 "github.com/duynhne/cart-service/internal/logic/v1"
+	"github.com/duynhne/cart-service/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{logicv1.ErrCartNotFound, http.StatusNotFound, "CART_NOT_FOUND"},
+		{logicv1.ErrCartEmpty, http.StatusBadRequest, "CART_EMPTY"},
+		{logicv1.ErrItemNotInCart, http.StatusNotFound, "ITEM_NOT_IN_CART"},
+		{logicv1.ErrCartItemNotFound, http.StatusNotFound, "CART_ITEM_NOT_FOUND"},
+		{logicv1.ErrCartModified, http.StatusPreconditionFailed, "CART_VERSION_MISMATCH"},
+		{logicv1.ErrInvalidQuantity, http.StatusBadRequest, "INVALID_QUANTITY"},
+		{logicv1.ErrInvalidPrice, http.StatusBadRequest, "INVALID_PRICE"},
+		{logicv1.ErrMixedCurrency, http.StatusConflict, "CURRENCY_MISMATCH"},
+		{logicv1.ErrUnsupportedCurrency, http.StatusBadRequest, "UNSUPPORTED_CURRENCY"},
+		{logicv1.ErrCouponNotFound, http.StatusNotFound, "COUPON_NOT_FOUND"},
+		{logicv1.ErrCouponNotApplicable, http.StatusUnprocessableEntity, "COUPON_NOT_APPLICABLE"},
+		{logicv1.ErrCouponUsageLimitReached, http.StatusConflict, "COUPON_USAGE_LIMIT_REACHED"},
+		{logicv1.ErrNoGuestCart, http.StatusBadRequest, "NO_GUEST_CART"},
+		{logicv1.ErrInsufficientStock, http.StatusBadRequest, "INSUFFICIENT_STOCK"},
+		{logicv1.ErrWebhookNotFound, http.StatusNotFound, "WEBHOOK_NOT_FOUND"},
+		{logicv1.ErrInvalidEventType, http.StatusBadRequest, "INVALID_EVENT_TYPE"},
+		{logicv1.ErrBatchTooLarge, http.StatusBadRequest, "BATCH_TOO_LARGE"},
+		{logicv1.ErrSnapshotNotFound, http.StatusNotFound, "SNAPSHOT_NOT_FOUND"},
+		{logicv1.ErrCheckoutClosed, http.StatusConflict, "CHECKOUT_CLOSED"},
+		{logicv1.ErrUnauthorized, http.StatusForbidden, "FORBIDDEN"},
+		{domain.ErrNotFound, http.StatusNotFound, "NOT_FOUND"},
+		{domain.ErrInvalidInput, http.StatusBadRequest, "INVALID_INPUT"},
+		{domain.ErrCurrencyMismatch, http.StatusConflict, "CURRENCY_MISMATCH"},
+		{domain.ErrVersionMismatch, http.StatusPreconditionFailed, "CART_VERSION_MISMATCH"},
+		{domain.ErrCartFull, http.StatusConflict, "CART_FULL"},
+		{domain.ErrCartLocked, http.StatusConflict, "CART_LOCKED"},
+		{domain.ErrConflict, http.StatusConflict, "CONFLICT"},
+		{errors.New("db error"), http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantCode+"/"+tt.err.Error(), func(t *testing.T) {
+			// Sentinels are matched through wrapping
+			got := mapError(fmt.Errorf("handler context: %w", tt.err))
+			assert.Equal(t, tt.wantStatus, got.Status)
+			assert.Equal(t, tt.wantCode, got.Code)
+			assert.NotEmpty(t, got.Message)
+		})
+	}
+}
+
+func TestItemHandlersMapErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		handler    func(h *CartHandler) gin.HandlerFunc
+		method     string
+		body       string
+		setup      func(m *MockCartRepository)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:    "Update missing item",
+			handler: func(h *CartHandler) gin.HandlerFunc { return h.UpdateCartItem },
+			method:  http.MethodPatch,
+			body:    `{"quantity": 2}`,
+			setup: func(m *MockCartRepository) {
+				m.On("UpdateItem", mock.Anything, "1", "42", 2).Return(domain.ErrNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   "CART_ITEM_NOT_FOUND",
+		},
+		{
+			name:       "Update invalid quantity",
+			handler:    func(h *CartHandler) gin.HandlerFunc { return h.UpdateCartItem },
+			method:     http.MethodPatch,
+			body:       `{"quantity": 0}`,
+			setup:      func(m *MockCartRepository) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   codeInvalidRequest,
+		},
+		{
+			name:    "Remove missing item",
+			handler: func(h *CartHandler) gin.HandlerFunc { return h.RemoveCartItem },
+			method:  http.MethodDelete,
+			setup: func(m *MockCartRepository) {
+				m.On("RemoveItem", mock.Anything, "1", "42").Return(domain.ErrNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   "CART_ITEM_NOT_FOUND",
+		},
+		{
+			name:    "Remove storage failure",
+			handler: func(h *CartHandler) gin.HandlerFunc { return h.RemoveCartItem },
+			method:  http.MethodDelete,
+			setup: func(m *MockCartRepository) {
+				m.On("RemoveItem", mock.Anything, "1", "42").Return(errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   codeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockCartRepository)
+			tt.setup(mockRepo)
+			handler := NewCartHandler(logicv1.NewCartService(mockRepo))
+
+			w := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(w)
+			r.Use(func(c *gin.Context) { c.Set("user_id", "1") })
+			r.Handle(tt.method, "/cart/items/:itemId", tt.handler(handler))
+
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, "/cart/items/42", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+			var body middleware.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantCode, body.Code)
+			assert.Equal(t, "/cart/items/42", body.Instance)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRespondBindError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantDetail string
+		wantParams []middleware.InvalidParam
+	}{
+		{
+			name:       "Missing fields",
+			body:       `{"product_name": "P", "product_price": 10}`,
+			wantDetail: "Request validation failed",
+			wantParams: []middleware.InvalidParam{
+				{Name: "product_id", Reason: "is required"},
+				{Name: "quantity", Reason: "is required"},
+			},
+		},
+		{
+			name:       "Bad currency",
+			body:       `{"product_id": "p1", "product_name": "P", "product_price": 10, "quantity": 1, "currency": "XYZ"}`,
+			wantDetail: "Request validation failed",
+			wantParams: []middleware.InvalidParam{{Name: "currency", Reason: "must be an ISO 4217 currency code"}},
+		},
+		{
+			name:       "Wrong type",
+			body:       `{"product_id": "p1", "product_name": "P", "product_price": 10, "quantity": "two"}`,
+			wantDetail: "Request validation failed",
+			wantParams: []middleware.InvalidParam{{Name: "quantity", Reason: "must be of type int"}},
+		},
+		{
+			name:       "Malformed JSON",
+			body:       `{"product_id":`,
+			wantDetail: "Request body is not valid JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCartHandler(logicv1.NewCartService(new(MockCartRepository)))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/cart", strings.NewReader(tt.body))
+			c.Set("user_id", "1")
+			c.Set("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736")
+
+			handler.AddToCart(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var body middleware.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, "INVALID_REQUEST", body.Code)
+			assert.Equal(t, tt.wantDetail, body.Detail)
+			assert.Equal(t, tt.wantParams, body.InvalidParams)
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", body.TraceID)
+		})
+	}
+}
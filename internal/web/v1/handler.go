@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 
 	"github.com/duynhne/pkg/logger/clog"
@@ -23,6 +25,24 @@ func NewCartHandler(cartService *logicv1.CartService) *CartHandler {
 	return &CartHandler{cartService: cartService}
 }
 
+// requireUserID returns the cart owner set by AuthMiddleware.
+// Handlers never fall back to a default identity; a missing owner is a 401.
+func requireUserID(c *gin.Context) (string, bool) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, middleware.CodeUnauthenticated, "Authentication required")
+		return "", false
+	}
+	return userID, true
+}
+
+// setCartETag exposes the cart version for If-Match.
+// Clients must revalidate since the cart changes outside their control.
+func setCartETag(c *gin.Context, cart *domain.Cart) {
+	c.Header("ETag", middleware.CartETag(cart.Version))
+	c.Header("Cache-Control", "private, no-cache")
+}
+
 func (h *CartHandler) GetCart(c *gin.Context) {
 	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
 		attribute.String("layer", "web"),
@@ -31,28 +51,45 @@ func (h *CartHandler) GetCart(c *gin.Context) {
 	))
 	defer span.End()
 
-	// Get userID from context/auth (for now, use a placeholder)
-	userID := c.GetString("user_id")
-	if userID == "" {
-		userID = "1" // Default for demo
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.GetCartRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Invalid request", "error", err)
+		respondBindError(c, err, &req)
+		return
 	}
 
-	cart, err := h.cartService.GetCart(ctx, userID)
+	cart, err := h.cartService.GetCart(ctx, userID, req)
 	if err != nil {
 		span.RecordError(err)
 		clog.ErrorContext(ctx, "Failed to get cart", "error", err)
+		respondError(c, err)
+		return
+	}
 
-		switch {
-		case errors.Is(err, logicv1.ErrCartNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		}
+	// The tag covers the rendered body: refreshed prices, stock and the
+	// requested currency change it without changing the version
+	body, err := json.Marshal(cart)
+	if err != nil {
+		span.RecordError(err)
+		respondError(c, err)
+		return
+	}
+	etag := middleware.CartRepresentationETag(cart.Version, body)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+	if inm := c.GetHeader("If-None-Match"); inm != "" && middleware.NoneMatch(inm, etag) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
 	clog.InfoContext(ctx, "Cart retrieved", "user_id", userID)
-	c.JSON(http.StatusOK, cart)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
 }
 
 func (h *CartHandler) AddToCart(c *gin.Context) {
@@ -63,10 +100,9 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 	))
 	defer span.End()
 
-	// Get userID from context/auth
-	userID := c.GetString("user_id")
-	if userID == "" {
-		userID = "1" // Default for demo
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
 	}
 
 	var req domain.AddToCartRequest
@@ -74,7 +110,7 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 		span.SetAttributes(attribute.Bool("request.valid", false))
 		span.RecordError(err)
 		clog.ErrorContext(ctx, "Invalid request", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err, &req)
 		return
 	}
 
@@ -83,7 +119,7 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 	if err != nil {
 		span.RecordError(err)
 		clog.ErrorContext(ctx, "Failed to add to cart", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		respondError(c, err)
 		return
 	}
 
@@ -99,16 +135,16 @@ func (h *CartHandler) GetCartCount(c *gin.Context) {
 	))
 	defer span.End()
 
-	userID := c.GetString("user_id")
-	if userID == "" {
-		userID = "1"
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
 	}
 
 	count, err := h.cartService.GetCartCount(ctx, userID)
 	if err != nil {
 		span.RecordError(err)
 		clog.ErrorContext(ctx, "Failed to get cart count", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		respondError(c, err)
 		return
 	}
 
@@ -123,9 +159,9 @@ func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 	))
 	defer span.End()
 
-	userID := c.GetString("user_id")
-	if userID == "" {
-		userID = "1"
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
 	}
 
 	itemID := c.Param("itemId")
@@ -136,7 +172,7 @@ func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 	if err := c.ShouldBindJSON(&req); err != nil {
 		span.RecordError(err)
 		clog.ErrorContext(ctx, "Invalid request", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err, &req)
 		return
 	}
 
@@ -144,7 +180,7 @@ func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 	if err != nil {
 		span.RecordError(err)
 		clog.ErrorContext(ctx, "Failed to update cart item", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		respondError(c, err)
 		return
 	}
 
@@ -159,9 +195,9 @@ func (h *CartHandler) RemoveCartItem(c *gin.Context) {
 	))
 	defer span.End()
 
-	userID := c.GetString("user_id")
-	if userID == "" {
-		userID = "1"
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
 	}
 
 	itemID := c.Param("itemId")
@@ -170,7 +206,7 @@ func (h *CartHandler) RemoveCartItem(c *gin.Context) {
 	if err != nil {
 		span.RecordError(err)
 		clog.ErrorContext(ctx, "Failed to remove cart item", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		respondError(c, err)
 		return
 	}
 
@@ -185,20 +221,149 @@ func (h *CartHandler) ClearCart(c *gin.Context) {
 	))
 	defer span.End()
 
-	userID := c.GetString("user_id")
-	if userID == "" {
-		userID = "1"
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
 	}
 
 	if err := h.cartService.ClearCart(ctx, userID); err != nil {
 		span.RecordError(err)
 		clog.ErrorContext(ctx, "Failed to clear cart", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Cart cleared"})
 }
 
+func (h *CartHandler) ApplyCoupon(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ApplyCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Invalid request", "error", err)
+		respondBindError(c, err, &req)
+		return
+	}
+
+	cart, err := h.cartService.ApplyCoupon(ctx, userID, req.Code)
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to apply coupon", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	clog.InfoContext(ctx, "Coupon applied", "user_id", userID, "code", req.Code)
+	setCartETag(c, cart)
+	c.JSON(http.StatusOK, cart)
+}
+
+func (h *CartHandler) RemoveCoupon(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	code := c.Param("code")
+
+	if err := h.cartService.RemoveCoupon(ctx, userID, code); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to remove coupon", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Coupon removed"})
+}
+
+func (h *CartHandler) MergeCart(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	guestID := c.GetString("guest_user_id")
+
+	var req domain.MergeCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Invalid request", "error", err)
+		respondBindError(c, err, &req)
+		return
+	}
+
+	cart, err := h.cartService.MergeGuestCart(ctx, userID, guestID, req.Policy)
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to merge cart", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	middleware.ClearCartToken(c)
+	clog.InfoContext(ctx, "Guest cart merged", "user_id", userID, "policy", req.Policy)
+	setCartETag(c, cart)
+	c.JSON(http.StatusOK, cart)
+}
+
+// UpdateCart handles PATCH /cart: changes cart-level fields such as the notes
+func (h *CartHandler) UpdateCart(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Invalid request", "error", err)
+		respondBindError(c, err, &req)
+		return
+	}
+
+	cart, err := h.cartService.UpdateCart(ctx, userID, req)
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to update cart", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	setCartETag(c, cart)
+	c.JSON(http.StatusOK, cart)
+}
+
 // Global state removed to comply with AGENTS.md dependency injection rules
 
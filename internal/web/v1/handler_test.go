@@ -11,6 +11,7 @@ import (
 
 	"github.com/duynhne/cart-service/internal/core/domain"
 	logicv1 "github.com/duynhne/cart-service/internal/logic/v1"
+	"github.com/duynhne/cart-service/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -34,6 +35,37 @@ func (m *MockCartRepository) GetItemCount(ctx context.Context, userID string) (i
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockCartRepository) UpdateCart(ctx context.Context, userID string, update domain.CartUpdate) error {
+	args := m.Called(ctx, userID, update)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) ListCarts(ctx context.Context, userID string) ([]domain.CartSummary, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CartSummary), args.Error(1)
+}
+
+func (m *MockCartRepository) CreateCart(ctx context.Context, userID, name string, kind domain.CartKind) (*domain.CartSummary, error) {
+	args := m.Called(ctx, userID, name, kind)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CartSummary), args.Error(1)
+}
+
+func (m *MockCartRepository) DeleteCart(ctx context.Context, userID, cartID string) error {
+	args := m.Called(ctx, userID, cartID)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) SetActiveCart(ctx context.Context, userID, cartID string) error {
+	args := m.Called(ctx, userID, cartID)
+	return args.Error(0)
+}
+
 func (m *MockCartRepository) AddItem(ctx context.Context, userID string, item *domain.CartItem) error {
 	args := m.Called(ctx, userID, item)
 	return args.Error(0)
@@ -54,6 +86,40 @@ func (m *MockCartRepository) Clear(ctx context.Context, userID string) error {
 	return args.Error(0)
 }
 
+func (m *MockCartRepository) ListSaved(ctx context.Context, userID string) ([]domain.CartItem, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CartItem), args.Error(1)
+}
+
+func (m *MockCartRepository) MoveItem(ctx context.Context, userID, itemID string, to domain.ItemList) (*domain.CartItem, error) {
+	args := m.Called(ctx, userID, itemID, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CartItem), args.Error(1)
+}
+
+func (m *MockCartRepository) RemoveSaved(ctx context.Context, userID, itemID string) error {
+	args := m.Called(ctx, userID, itemID)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) Merge(ctx context.Context, guestID, userID string, policy domain.MergePolicy) error {
+	args := m.Called(ctx, guestID, userID, policy)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) ApplyBatch(ctx context.Context, userID string, ops []domain.BatchOp, atomic bool) ([]error, error) {
+	args := m.Called(ctx, userID, ops, atomic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]error), args.Error(1)
+}
+
 func TestGetCart(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -98,6 +164,74 @@ func TestGetCart(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("ETag", func(t *testing.T) {
+		mockRepo := new(MockCartRepository)
+		mockRepo.On("FindByUserID", mock.Anything, "1").Return(&domain.Cart{UserID: "1", Version: 4}, nil)
+
+		service := logicv1.NewCartService(mockRepo)
+		handler := NewCartHandler(service)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/cart", nil)
+		c.Set("user_id", "1")
+
+		handler.GetCart(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, middleware.CartRepresentationETag(4, w.Body.Bytes()), w.Header().Get("ETag"))
+	})
+
+	t.Run("NotModified", func(t *testing.T) {
+		cart := &domain.Cart{UserID: "1", Version: 4, Currency: domain.DefaultCurrency}
+		mockRepo := new(MockCartRepository)
+		mockRepo.On("FindByUserID", mock.Anything, "1").Return(cart, nil)
+
+		service := logicv1.NewCartService(mockRepo)
+		handler := NewCartHandler(service)
+
+		get := func(inm string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/cart", nil)
+			c.Request.Header.Set("If-None-Match", inm)
+			c.Set("user_id", "1")
+			handler.GetCart(c)
+			c.Writer.WriteHeaderNow()
+			return w
+		}
+
+		etag := get("").Header().Get("ETag")
+		w := get(etag)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+
+		// A version-only tag no longer matches the representation
+		assert.Equal(t, http.StatusOK, get(`"4"`).Code)
+
+		// Repricing changes the body but not the version
+		cart.Items = []domain.CartItem{{ID: "a", ProductID: "1", ProductPrice: domain.NewMoney(1000, domain.DefaultCurrency), Quantity: 1}}
+		w = get(etag)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		mockRepo := new(MockCartRepository)
+
+		service := logicv1.NewCartService(mockRepo)
+		handler := NewCartHandler(service)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/cart", nil)
+
+		handler.GetCart(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockRepo.AssertNotCalled(t, "FindByUserID", mock.Anything, mock.Anything)
+	})
 }
 
 func TestAddToCart(t *testing.T) {
@@ -108,7 +242,7 @@ func TestAddToCart(t *testing.T) {
 		req := domain.AddToCartRequest{
 			ProductID:    "p1",
 			ProductName:  "Product 1",
-			ProductPrice: 10.0,
+			ProductPrice: domain.NewMoney(1000, domain.DefaultCurrency),
 			Quantity:     1,
 		}
 
@@ -153,7 +287,7 @@ func TestAddToCart(t *testing.T) {
 		req := domain.AddToCartRequest{
 			ProductID:    "p1",
 			ProductName:  "Product 1",
-			ProductPrice: 10.0,
+			ProductPrice: domain.NewMoney(1000, domain.DefaultCurrency),
 			Quantity:     1,
 		}
 
@@ -174,3 +308,74 @@ func TestAddToCart(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestBatchItems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(mockRepo *MockCartRepository) *gin.Engine {
+		handler := NewCartHandler(logicv1.NewCartService(mockRepo))
+		r := gin.New()
+		r.Use(func(c *gin.Context) { c.Set("user_id", "1") })
+		r.POST("/cart/items\\:batch", handler.BatchItems)
+		r.DELETE("/cart/items/:itemId", handler.RemoveCartItem)
+		return r
+	}
+	post := func(r *gin.Engine, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/cart/items:batch", bytes.NewBufferString(body)))
+		return w
+	}
+	cart := &domain.Cart{ID: "7", UserID: "1", Version: 3, Currency: domain.DefaultCurrency, Items: []domain.CartItem{}}
+
+	t.Run("BestEffort", func(t *testing.T) {
+		mockRepo := new(MockCartRepository)
+		mockRepo.On("ApplyBatch", mock.Anything, "1", mock.MatchedBy(func(ops []domain.BatchOp) bool {
+			return len(ops) == 2 && ops[0].Type == domain.BatchAdd && ops[1].Type == domain.BatchRemove
+		}), false).Return([]error{nil, domain.ErrNotFound}, nil)
+		mockRepo.On("FindByUserID", mock.Anything, "1").Return(cart, nil)
+
+		w := post(newRouter(mockRepo), `{"mode": "best_effort", "operations": [
+			{"op": "add", "item": {"product_id": "1", "product_name": "Mug", "product_price": "9.99", "quantity": 2}},
+			{"op": "update", "item_id": "5", "quantity": 0},
+			{"op": "remove", "item_id": "6"}
+		]}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp batchResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.Applied)
+		if assert.Len(t, resp.Results, 3) {
+			assert.Equal(t, domain.BatchOpApplied, resp.Results[0].Status)
+			assert.Equal(t, domain.BatchOpFailed, resp.Results[1].Status)
+			assert.Equal(t, "INVALID_QUANTITY", resp.Results[1].Error.Code)
+			assert.Equal(t, "CART_ITEM_NOT_FOUND", resp.Results[2].Error.Code)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AtomicFailure", func(t *testing.T) {
+		mockRepo := new(MockCartRepository)
+		mockRepo.On("FindByUserID", mock.Anything, "1").Return(cart, nil)
+
+		w := post(newRouter(mockRepo), `{"operations": [
+			{"op": "remove", "item_id": "6"},
+			{"op": "update", "item_id": "5", "quantity": 0}
+		]}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var resp batchResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, domain.BatchAtomic, resp.Mode)
+		assert.False(t, resp.Applied)
+		if assert.Len(t, resp.Results, 2) {
+			assert.Equal(t, domain.BatchOpNotApplied, resp.Results[0].Status)
+			assert.Equal(t, domain.BatchOpFailed, resp.Results[1].Status)
+		}
+		mockRepo.AssertNotCalled(t, "ApplyBatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AddWithoutItem", func(t *testing.T) {
+		w := post(newRouter(new(MockCartRepository)), `{"operations": [{"op": "add"}]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,25 @@
+package v1
+
+import (
+	logicv1 "github.com/duynhne/cart-service/internal/logic/v1"
+	"github.com/duynhne/pkg/logger/clog"
+	"github.com/gin-gonic/gin"
+)
+
+// maxUserIDLength matches carts.user_id
+const maxUserIDLength = 64
+
+// ActAsUser makes the /cart handlers act for the user named by the :userId
+// route parameter. Internal routes use it behind ServiceAuth, where the caller
+// is a service rather than the cart owner.
+func ActAsUser(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" || len(userID) > maxUserIDLength {
+		respondError(c, logicv1.ErrCartNotFound)
+		return
+	}
+	clog.DebugContext(c.Request.Context(), "Internal cart request",
+		"service", c.GetString("service_name"), "user_id", userID)
+	c.Set("user_id", userID)
+	c.Next()
+}
@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/duynhne/cart-service/middleware"
+	"github.com/duynhne/pkg/logger/clog"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ListSaved handles GET /cart/saved
+func (h *CartHandler) ListSaved(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.cartService.ListSaved(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to list saved items", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// SaveForLater handles POST /cart/items/:itemId/save-for-later
+func (h *CartHandler) SaveForLater(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.cartService.SaveForLater(ctx, userID, c.Param("itemId"))
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to save item for later", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	clog.InfoContext(ctx, "Item saved for later", "user_id", userID, "product_id", item.ProductID)
+	c.JSON(http.StatusOK, item)
+}
+
+// MoveToCart handles POST /cart/saved/:itemId/move-to-cart
+func (h *CartHandler) MoveToCart(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.cartService.MoveToCart(ctx, userID, c.Param("itemId"))
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to move saved item to cart", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	clog.InfoContext(ctx, "Saved item moved to cart", "user_id", userID, "product_id", item.ProductID)
+	c.JSON(http.StatusOK, item)
+}
+
+// RemoveSavedItem handles DELETE /cart/saved/:itemId
+func (h *CartHandler) RemoveSavedItem(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.cartService.RemoveSavedItem(ctx, userID, c.Param("itemId")); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to remove saved item", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Saved item removed"})
+}
@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	logicv1 "github.com/duynhne/cart-service/internal/logic/v1"
+	"github.com/duynhne/cart-service/middleware"
+	"github.com/duynhne/pkg/logger/clog"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WebhookHandler serves the webhook subscription admin API
+type WebhookHandler struct {
+	webhookService *logicv1.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService *logicv1.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// deliveryLogQuery filters GET /webhooks/:webhookId/deliveries
+type deliveryLogQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// webhookIDParam returns the :name route parameter, answering 404 if it is not a valid ID
+func webhookIDParam(c *gin.Context, name string) (string, bool) {
+	id := c.Param(name)
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		respondError(c, logicv1.ErrWebhookNotFound)
+		return "", false
+	}
+	return id, true
+}
+
+// CreateWebhook handles POST /webhooks
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	var req domain.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Invalid request", "error", err)
+		respondBindError(c, err, &req)
+		return
+	}
+
+	sub, err := h.webhookService.CreateSubscription(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to create webhook subscription", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	clog.InfoContext(ctx, "Webhook subscription created", "webhook_id", sub.ID, "url", sub.URL)
+	c.JSON(http.StatusCreated, sub)
+}
+
+// ListWebhooks handles GET /webhooks
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	subs, err := h.webhookService.ListSubscriptions(ctx)
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to list webhook subscriptions", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": subs})
+}
+
+// DeleteWebhook handles DELETE /webhooks/:webhookId
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	id, ok := webhookIDParam(c, "webhookId")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(ctx, id); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to delete webhook subscription", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	clog.InfoContext(ctx, "Webhook subscription deleted", "webhook_id", id)
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /webhooks/:webhookId/deliveries
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	id, ok := webhookIDParam(c, "webhookId")
+	if !ok {
+		return
+	}
+
+	var query deliveryLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Invalid request", "error", err)
+		respondBindError(c, err, &query)
+		return
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(ctx, id, domain.DeliveryStatus(query.Status), query.Limit)
+	if err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to list webhook deliveries", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// RedeliverWebhook handles POST /webhooks/:webhookId/deliveries/:deliveryId/redeliver
+func (h *WebhookHandler) RedeliverWebhook(c *gin.Context) {
+	ctx, span := middleware.StartSpan(c.Request.Context(), "http.request", trace.WithAttributes(
+		attribute.String("layer", "web"),
+		attribute.String("method", c.Request.Method),
+		attribute.String("path", c.Request.URL.Path),
+	))
+	defer span.End()
+
+	id, ok := webhookIDParam(c, "webhookId")
+	if !ok {
+		return
+	}
+	deliveryID, ok := webhookIDParam(c, "deliveryId")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.Redeliver(ctx, id, deliveryID); err != nil {
+		span.RecordError(err)
+		clog.ErrorContext(ctx, "Failed to redeliver webhook", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	clog.InfoContext(ctx, "Webhook delivery rescheduled", "webhook_id", id, "delivery_id", deliveryID)
+	c.Status(http.StatusAccepted)
+}
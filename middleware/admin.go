@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth protects operator endpoints with a static bearer token shared with
+// the operators' tooling. Tokens are compared in constant time.
+func AdminAuth(token string) gin.HandlerFunc {
+	want := sha256.Sum256([]byte(token))
+	return func(c *gin.Context) {
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		gotSum := sha256.Sum256([]byte(got))
+		if !ok || token == "" || subtle.ConstantTimeCompare(gotSum[:], want[:]) != 1 {
+			AbortWithProblem(c, http.StatusUnauthorized, CodeUnauthenticated, "A valid admin token is required")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin", AdminAuth("0123456789abcdef0123456789abcdef"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{"Bearer 0123456789abcdef0123456789abcdef", http.StatusNoContent},
+		{"Bearer 0123456789abcdef0123456789abcdee", http.StatusUnauthorized},
+		{"0123456789abcdef0123456789abcdef", http.StatusUnauthorized},
+		{"", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("Authorization %q: status = %d, want %d", tt.header, w.Code, tt.want)
+		}
+	}
+}
@@ -2,17 +2,39 @@ package middleware
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/rand/v2"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/duynhne/cart-service/internal/core/domain"
 	"github.com/duynhne/pkg/logger/clog"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
 )
 
+// ErrInvalidToken indicates the auth service rejected the bearer token
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// AuthMode selects how AuthMiddleware handles requests it cannot authenticate
+type AuthMode string
+
+const (
+	// AuthModeStrict rejects malformed or invalid tokens with 401 and auth outages with 503
+	AuthModeStrict AuthMode = "strict"
+	// AuthModeDemo falls back to user "1" instead; for local demos only, refused in production
+	AuthModeDemo AuthMode = "demo"
+)
+
+// demoUserID is the identity used by AuthModeDemo when authentication fails
+const demoUserID = "1"
+
 // AuthUser represents the user info returned from auth service
 type AuthUser struct {
 	ID       string `json:"id"`
@@ -20,25 +42,145 @@ type AuthUser struct {
 	Email    string `json:"email"`
 }
 
+// Defaults for the auth service call policy; see the AuthClient options
+const (
+	defaultAuthTimeout      = 2 * time.Second
+	defaultAuthRetries      = 2
+	defaultAuthRetryBackoff = 100 * time.Millisecond
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
 // AuthClient handles communication with the auth service
 type AuthClient struct {
 	baseURL    string
 	httpClient *http.Client
+
+	retries int           // Extra attempts after a retryable failure
+	backoff time.Duration // Base delay, doubled per attempt with full jitter
+	breaker *circuitBreaker
+
+	cache       *tokenCache // nil disables caching
+	ttl         time.Duration
+	negativeTTL time.Duration
+	lookups     singleflight.Group
+}
+
+// AuthClientOption configures optional AuthClient behaviour
+type AuthClientOption func(*AuthClient)
+
+// WithTokenCache caches up to size introspection results. Valid tokens are cached
+// for ttl (never past the token's own expiry), rejected tokens for negativeTTL.
+func WithTokenCache(size int, ttl, negativeTTL time.Duration) AuthClientOption {
+	return func(c *AuthClient) {
+		if size <= 0 {
+			return
+		}
+		c.cache = newTokenCache(size)
+		c.ttl = ttl
+		c.negativeTTL = negativeTTL
+	}
+}
+
+// WithTimeout bounds each call to the auth service, retries included separately
+func WithTimeout(timeout time.Duration) AuthClientOption {
+	return func(c *AuthClient) {
+		if timeout > 0 {
+			c.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// WithRetries retries transport errors, 429 and 5xx responses up to retries times,
+// waiting a random delay of up to backoff, 2*backoff, 4*backoff... between attempts.
+func WithRetries(retries int, backoff time.Duration) AuthClientOption {
+	return func(c *AuthClient) {
+		c.retries = max(retries, 0)
+		c.backoff = backoff
+	}
+}
+
+// WithCircuitBreaker opens the breaker after threshold consecutive failed calls.
+// While open, calls fail fast with ErrCircuitOpen; after cooldown one probe is let through.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) AuthClientOption {
+	return func(c *AuthClient) {
+		if threshold > 0 && cooldown > 0 {
+			c.breaker = newCircuitBreaker(threshold, cooldown)
+		}
+	}
 }
 
 // NewAuthClient creates a new auth client
-func NewAuthClient(baseURL string) *AuthClient {
-	return &AuthClient{
+func NewAuthClient(baseURL string, opts ...AuthClientOption) *AuthClient {
+	c := &AuthClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 5 * time.Second,
+			Timeout: defaultAuthTimeout,
 		},
+		retries: defaultAuthRetries,
+		backoff: defaultAuthRetryBackoff,
+		breaker: newCircuitBreaker(defaultBreakerThreshold, defaultBreakerCooldown),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-// GetMe retrieves user info from auth service using the token
-func (c *AuthClient) GetMe(token string) (*AuthUser, error) {
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, c.baseURL+"/auth/v1/private/me", nil)
+// GetMe retrieves user info from auth service using the token.
+// Transport errors, 429 and 5xx responses are retried; every failure that reaches
+// the caller wraps ErrAuthUnavailable, except ErrInvalidToken and ctx errors.
+func (c *AuthClient) GetMe(ctx context.Context, token string) (*AuthUser, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.breaker.allow(); err != nil {
+			return nil, err
+		}
+
+		user, err := c.getMe(ctx, token)
+		switch {
+		case err == nil || errors.Is(err, ErrInvalidToken):
+			c.breaker.success()
+			return user, err
+		case ctx.Err() != nil:
+			// The caller gave up; that says nothing about the auth service
+			c.breaker.release()
+			return nil, ctx.Err()
+		}
+		c.breaker.failure()
+
+		var statusErr *authStatusError
+		retryable := !errors.As(err, &statusErr) || statusErr.retryable()
+		if !retryable || attempt >= c.retries {
+			return nil, fmt.Errorf("%w: %w", ErrAuthUnavailable, err)
+		}
+
+		delay := rand.N(c.backoff<<attempt + 1) // #nosec G404 -- jitter does not need a CSPRNG
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// authStatusError is an unexpected HTTP status from the auth service
+type authStatusError struct {
+	status int
+	body   string
+}
+
+func (e *authStatusError) Error() string {
+	return fmt.Sprintf("auth service error: %d - %s", e.status, e.body)
+}
+
+// retryable reports whether the status is a transient failure worth retrying
+func (e *authStatusError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= http.StatusInternalServerError
+}
+
+// getMe makes a single GET /auth/v1/private/me call
+func (c *AuthClient) getMe(ctx context.Context, token string) (*AuthUser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/auth/v1/private/me", nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -51,11 +193,11 @@ func (c *AuthClient) GetMe(token string) (*AuthUser, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, errors.New("invalid or expired token")
+		return nil, ErrInvalidToken
 	}
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("auth service error: %d - %s", resp.StatusCode, string(body))
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, &authStatusError{status: resp.StatusCode, body: string(body)}
 	}
 
 	var user AuthUser
@@ -66,16 +208,90 @@ func (c *AuthClient) GetMe(token string) (*AuthUser, error) {
 	return &user, nil
 }
 
-// AuthMiddleware creates a middleware that validates tokens via auth service
-// It sets "user_id" in the gin context if authentication succeeds
-func AuthMiddleware(authClient *AuthClient) gin.HandlerFunc {
+// BreakerState returns the state of the auth service circuit breaker
+func (c *AuthClient) BreakerState() BreakerState {
+	return c.breaker.current()
+}
+
+// Ready implements ReadinessChecker: the client is not ready while its breaker is open
+func (c *AuthClient) Ready() bool {
+	return c.BreakerState() != BreakerOpen
+}
+
+// Authenticate implements Authenticator using remote introspection (GET /auth/v1/private/me).
+// With a token cache, results are served from memory and concurrent lookups of the
+// same token share one auth service call. The shared call is detached from any single
+// request's cancellation; each caller still stops waiting when its own ctx is done.
+func (c *AuthClient) Authenticate(ctx context.Context, token string) (*AuthUser, error) {
+	if c.cache == nil {
+		return c.GetMe(ctx, token)
+	}
+
+	key := tokenKey(sha256.Sum256([]byte(token)))
+	if entry, ok := c.cache.get(key); ok {
+		if entry.user == nil {
+			authCacheLookups.WithLabelValues("negative_hit").Inc()
+			return nil, ErrInvalidToken
+		}
+		authCacheLookups.WithLabelValues("hit").Inc()
+		return entry.user, nil
+	}
+	authCacheLookups.WithLabelValues("miss").Inc()
+
+	lookupCtx := context.WithoutCancel(ctx)
+	ch := c.lookups.DoChan(string(key[:]), func() (any, error) {
+		user, err := c.GetMe(lookupCtx, token)
+		now := c.cache.now()
+		switch {
+		case err == nil:
+			expires := now.Add(c.ttl)
+			if exp, ok := tokenExpiry(token); ok && exp.Before(expires) {
+				expires = exp
+			}
+			c.cache.put(key, user, expires)
+		case errors.Is(err, ErrInvalidToken):
+			c.cache.put(key, nil, now.Add(c.negativeTTL))
+		}
+		// Outages are not cached so recovery is immediate
+		return user, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*AuthUser), nil
+	}
+}
+
+// AuthMiddleware creates a middleware that validates bearer tokens with the authenticator
+// It sets "user_id" in the gin context if authentication succeeds.
+// Requests without an Authorization header get a guest cart: "user_id" is the
+// guest owner ID from the signed cart token, and a new token is issued when the
+// request carries none. Authenticated requests that also carry a valid cart token
+// get "guest_user_id" set so the guest cart can be merged.
+// What happens to malformed or rejected tokens depends on mode.
+func AuthMiddleware(authenticator Authenticator, cartTokens *CartTokenSigner, mode AuthMode) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			// No token provided - allow request with default user_id for demo compatibility
-			// In production, you'd return 401 here
-			c.Set("user_id", "1")
+			guestID, ok := cartTokens.guestIDFromRequest(c)
+			if !ok {
+				token, id, err := cartTokens.Issue()
+				if err != nil {
+					clog.ErrorContext(c.Request.Context(), "Failed to issue cart token", "error", err)
+					AbortWithProblem(c, http.StatusInternalServerError, CodeInternal, "Could not start a guest cart")
+					return
+				}
+				setCartToken(c, token)
+				guestID = id
+			}
+			c.Set("user_id", domain.GuestOwnerID(guestID))
+			c.Set("guest", true)
 			c.Next()
 			return
 		}
@@ -83,28 +299,48 @@ func AuthMiddleware(authClient *AuthClient) gin.HandlerFunc {
 		// Extract token from "Bearer <token>"
 		const bearerPrefix = "Bearer "
 		if len(authHeader) <= len(bearerPrefix) || authHeader[:len(bearerPrefix)] != bearerPrefix {
-			c.Set("user_id", "1")
-			c.Next()
+			if mode == AuthModeDemo {
+				c.Set("user_id", demoUserID)
+				c.Next()
+				return
+			}
+			AbortWithProblem(c, http.StatusUnauthorized, CodeUnauthenticated, "Authorization header must use the Bearer scheme")
 			return
 		}
 		token := authHeader[len(bearerPrefix):]
 
-		// Call auth service to validate token
-		user, err := authClient.GetMe(token)
+		// Validate token (locally against JWKS, or via the auth service)
+		user, err := authenticator.Authenticate(c.Request.Context(), token)
 		if err != nil {
 			logger := clog.FromContext(c.Request.Context())
 			logger.DebugContext(c.Request.Context(), "Auth validation failed", "error", err)
 
-			// For demo compatibility, fall back to default user_id
-			// In production: c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Set("user_id", "1")
-			c.Next()
+			switch {
+			case mode == AuthModeDemo:
+				c.Set("user_id", demoUserID)
+				c.Next()
+			case errors.Is(err, ErrInvalidToken):
+				AbortWithProblem(c, http.StatusUnauthorized, CodeUnauthenticated, "Invalid or expired token")
+			case errors.Is(err, ErrCircuitOpen):
+				var open *circuitOpenError
+				if errors.As(err, &open) {
+					c.Header("Retry-After", strconv.Itoa(int(math.Ceil(open.retryAfter.Seconds()))))
+				}
+				logger.WarnContext(c.Request.Context(), "Auth circuit breaker open", "error", err)
+				AbortWithProblem(c, http.StatusServiceUnavailable, CodeAuthUnavailable, "Authentication service is unavailable, retry later")
+			default:
+				logger.ErrorContext(c.Request.Context(), "Auth service unavailable", "error", err)
+				AbortWithProblem(c, http.StatusServiceUnavailable, CodeAuthUnavailable, "Authentication is temporarily unavailable")
+			}
 			return
 		}
 
 		// Set user_id in context for handlers to use
 		c.Set("user_id", user.ID)
 		c.Set("username", user.Username)
+		if guestID, ok := cartTokens.guestIDFromRequest(c); ok {
+			c.Set("guest_user_id", domain.GuestOwnerID(guestID))
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newAuthTestRouter(authURL string, mode AuthMode) *gin.Engine {
+	return newAuthTestRouterWith(NewAuthClient(authURL, WithRetries(0, 0)), mode)
+}
+
+func newAuthTestRouterWith(authenticator Authenticator, mode AuthMode) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(AuthMiddleware(authenticator, NewCartTokenSigner("0123456789abcdef0123456789abcdef"), mode))
+	r.GET("/cart", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("user_id"))
+	})
+	return r
+}
+
+func TestAuthMiddlewareModes(t *testing.T) {
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer good":
+			_, _ = w.Write([]byte(`{"id": "42", "username": "bob"}`))
+		case "Bearer down":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer auth.Close()
+
+	tests := []struct {
+		name       string
+		mode       AuthMode
+		header     string
+		wantStatus int
+		wantUser   string
+	}{
+		{"Strict valid token", AuthModeStrict, "Bearer good", http.StatusOK, "42"},
+		{"Strict malformed header", AuthModeStrict, "Basic abc", http.StatusUnauthorized, ""},
+		{"Strict invalid token", AuthModeStrict, "Bearer bad", http.StatusUnauthorized, ""},
+		{"Strict auth outage", AuthModeStrict, "Bearer down", http.StatusServiceUnavailable, ""},
+		{"Demo invalid token", AuthModeDemo, "Bearer bad", http.StatusOK, demoUserID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/cart", nil)
+			req.Header.Set("Authorization", tt.header)
+			newAuthTestRouter(auth.URL, tt.mode).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantUser != "" && w.Body.String() != tt.wantUser {
+				t.Errorf("user_id = %q, want %q", w.Body.String(), tt.wantUser)
+			}
+			if tt.wantStatus >= 400 && w.Header().Get("Content-Type") != ProblemContentType {
+				t.Errorf("Content-Type = %q, want %q", w.Header().Get("Content-Type"), ProblemContentType)
+			}
+		})
+	}
+}
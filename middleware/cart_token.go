@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Guest cart token transport. Clients may send the token either as a header
+// (mobile, SPA) or rely on the cookie set by AuthMiddleware.
+const (
+	CartTokenHeader = "X-Cart-Token"
+	CartTokenCookie = "cart_token"
+
+	cartTokenMaxAge = 30 * 24 * 60 * 60 // 30 days, in seconds
+)
+
+// ErrInvalidCartToken indicates a cart token that is malformed or not signed by this service
+var ErrInvalidCartToken = errors.New("invalid cart token")
+
+// CartTokenSigner issues and verifies opaque guest cart tokens.
+// A token is "<guest id>.<HMAC-SHA256 of the id>", both parts base64url encoded,
+// so the server needs no storage to recognise its own tokens.
+type CartTokenSigner struct {
+	secret []byte
+}
+
+// NewCartTokenSigner creates a signer using the shared secret (CART_TOKEN_SECRET)
+func NewCartTokenSigner(secret string) *CartTokenSigner {
+	return &CartTokenSigner{secret: []byte(secret)}
+}
+
+// Issue creates a token for a new random guest ID
+func (s *CartTokenSigner) Issue() (token, guestID string, err error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	guestID = hex.EncodeToString(raw)
+	return base64.RawURLEncoding.EncodeToString([]byte(guestID)) + "." + s.sign(guestID), guestID, nil
+}
+
+// Verify checks the signature and returns the guest ID carried by the token
+func (s *CartTokenSigner) Verify(token string) (string, error) {
+	encodedID, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrInvalidCartToken
+	}
+	id, err := base64.RawURLEncoding.DecodeString(encodedID)
+	if err != nil || len(id) == 0 {
+		return "", ErrInvalidCartToken
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.sign(string(id)))) {
+		return "", ErrInvalidCartToken
+	}
+	return string(id), nil
+}
+
+func (s *CartTokenSigner) sign(guestID string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(guestID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// guestIDFromRequest returns the guest ID of a valid cart token sent with the request.
+// The header takes precedence over the cookie.
+func (s *CartTokenSigner) guestIDFromRequest(c *gin.Context) (string, bool) {
+	token := c.GetHeader(CartTokenHeader)
+	if token == "" {
+		token, _ = c.Cookie(CartTokenCookie)
+	}
+	if token == "" {
+		return "", false
+	}
+	id, err := s.Verify(token)
+	return id, err == nil
+}
+
+// setCartToken returns the token to the client in both the header and the cookie
+func setCartToken(c *gin.Context, token string) {
+	c.Header(CartTokenHeader, token)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(CartTokenCookie, token, cartTokenMaxAge, "/", "", isSecureRequest(c), true)
+}
+
+// ClearCartToken removes the guest cart cookie, e.g. after the guest cart was merged
+func ClearCartToken(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(CartTokenCookie, "", -1, "/", "", isSecureRequest(c), true)
+}
+
+func isSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+}
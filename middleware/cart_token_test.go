@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCartTokenSigner(t *testing.T) {
+	signer := NewCartTokenSigner("0123456789abcdef0123456789abcdef")
+
+	token, guestID, err := signer.Issue()
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	got, err := signer.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if got != guestID {
+		t.Errorf("Verify() = %q, want %q", got, guestID)
+	}
+
+	other := NewCartTokenSigner("fedcba9876543210fedcba9876543210")
+	if _, err := other.Verify(token); !errors.Is(err, ErrInvalidCartToken) {
+		t.Errorf("foreign signer error = %v, want ErrInvalidCartToken", err)
+	}
+
+	encodedID, sig, _ := strings.Cut(token, ".")
+	tampered := strings.ToUpper(encodedID[:1]) + encodedID[1:] + "." + sig
+	if tampered != token {
+		if _, err := signer.Verify(tampered); !errors.Is(err, ErrInvalidCartToken) {
+			t.Errorf("tampered token error = %v, want ErrInvalidCartToken", err)
+		}
+	}
+	if _, err := signer.Verify("not-a-token"); !errors.Is(err, ErrInvalidCartToken) {
+		t.Errorf("malformed token error = %v, want ErrInvalidCartToken", err)
+	}
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ErrCircuitOpen indicates the auth service circuit breaker is rejecting calls
+var ErrCircuitOpen = errors.New("auth circuit breaker open")
+
+var authBreakerState = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "auth_circuit_breaker_state",
+		Help: "Auth service circuit breaker state (0 closed, 1 half-open, 2 open)",
+	},
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // Calls pass through
+	BreakerHalfOpen                     // One probe call is let through to test recovery
+	BreakerOpen                         // Calls are rejected until the cooldown expires
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half_open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// circuitOpenError is returned while the breaker rejects calls. It matches both
+// ErrCircuitOpen and ErrAuthUnavailable and carries the remaining cooldown.
+type circuitOpenError struct {
+	retryAfter time.Duration
+}
+
+func (e *circuitOpenError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrCircuitOpen, e.retryAfter.Round(time.Second))
+}
+
+func (e *circuitOpenError) Unwrap() []error {
+	return []error{ErrCircuitOpen, ErrAuthUnavailable}
+}
+
+// circuitBreaker opens after threshold consecutive failures and rejects calls for
+// cooldown. After the cooldown a single probe is allowed (half-open): success closes
+// the breaker, failure opens it for another cooldown.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool // a half-open probe is in flight
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	authBreakerState.Set(float64(BreakerClosed))
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow reports whether a call may proceed. A nil error in half-open state reserves
+// the probe; the caller must then call success, failure or release.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		if remaining := b.cooldown - b.now().Sub(b.openedAt); remaining > 0 {
+			return &circuitOpenError{retryAfter: remaining}
+		}
+		b.setState(BreakerHalfOpen)
+	}
+	if b.state == BreakerHalfOpen {
+		if b.probing {
+			return &circuitOpenError{retryAfter: time.Second}
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// success records a call that reached a healthy dependency and closes the breaker
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	b.failures = 0
+	b.setState(BreakerClosed)
+}
+
+// failure records a failed call, opening the breaker on a failed probe or
+// once threshold consecutive failures are reached
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		b.setState(BreakerOpen)
+	}
+}
+
+// release ends a call whose outcome says nothing about the dependency,
+// such as one cancelled by the client
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// current returns the current state. An open breaker whose cooldown has expired is
+// reported as half-open so readiness recovers and traffic can probe the dependency.
+func (b *circuitBreaker) current() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// setState must be called with mu held
+func (b *circuitBreaker) setState(s BreakerState) {
+	if b.state == s {
+		return
+	}
+	b.state = s
+	authBreakerState.Set(float64(s))
+}
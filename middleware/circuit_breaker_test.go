@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.failure()
+	if got := b.current(); got != BreakerClosed {
+		t.Fatalf("after 1 failure state = %v, want closed", got)
+	}
+	b.failure()
+	if got := b.current(); got != BreakerOpen {
+		t.Fatalf("after 2 failures state = %v, want open", got)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, ErrAuthUnavailable) {
+		t.Fatalf("allow() while open = %v, want ErrCircuitOpen", err)
+	}
+
+	now = now.Add(time.Minute)
+	if got := b.current(); got != BreakerHalfOpen {
+		t.Fatalf("after cooldown state = %v, want half_open", got)
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("probe allow() = %v, want nil", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second probe allow() = %v, want ErrCircuitOpen", err)
+	}
+	b.failure()
+	if got := b.current(); got != BreakerOpen {
+		t.Fatalf("after failed probe state = %v, want open", got)
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.allow(); err != nil {
+		t.Fatalf("probe allow() = %v, want nil", err)
+	}
+	b.success()
+	if got := b.current(); got != BreakerClosed {
+		t.Fatalf("after successful probe state = %v, want closed", got)
+	}
+}
+
+func TestAuthClientRetries(t *testing.T) {
+	var calls atomic.Int32
+	var failures atomic.Int32
+	var status atomic.Int32
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(int(status.Load()))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "42"}`))
+	}))
+	defer auth.Close()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		status    int
+		failures  int32
+		wantErr   bool
+		wantCalls int32
+	}{
+		{"Recovers within budget", http.StatusServiceUnavailable, 2, false, 3},
+		{"Gives up after budget", http.StatusBadGateway, 5, true, 3},
+		{"Client errors are not retried", http.StatusBadRequest, 1, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			failures.Store(tt.failures)
+			status.Store(int32(tt.status))
+			client := NewAuthClient(auth.URL, WithRetries(2, time.Millisecond))
+
+			user, err := client.GetMe(ctx, "token")
+			if tt.wantErr {
+				if !errors.Is(err, ErrAuthUnavailable) {
+					t.Fatalf("error = %v, want ErrAuthUnavailable", err)
+				}
+			} else if err != nil || user.ID != "42" {
+				t.Fatalf("GetMe() = %v, %v; want user 42", user, err)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("auth calls = %d, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestAuthClientBreakerFailsFast(t *testing.T) {
+	var calls atomic.Int32
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer auth.Close()
+
+	client := NewAuthClient(auth.URL, WithRetries(0, 0), WithCircuitBreaker(2, time.Minute))
+	ctx := context.Background()
+
+	for range 2 {
+		_, _ = client.GetMe(ctx, "token")
+	}
+	if client.Ready() {
+		t.Fatal("Ready() = true with open breaker")
+	}
+
+	_, err := client.GetMe(ctx, "token")
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want ErrCircuitOpen", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("auth calls = %d, want 2", calls.Load())
+	}
+
+	// The middleware answers 503 with Retry-After without calling the auth service
+	r := newAuthTestRouterWith(client, AuthModeStrict)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/cart", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("status = %d, Retry-After = %q; want 503 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+}
+
+func TestAuthClientHonoursContext(t *testing.T) {
+	release := make(chan struct{})
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer auth.Close()
+	defer close(release)
+
+	client := NewAuthClient(auth.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetMe(ctx, "token")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetMe returned after %v, want promptly after cancellation", elapsed)
+	}
+	if got := client.BreakerState(); got != BreakerClosed {
+		t.Errorf("breaker state = %v, want closed after a cancelled call", got)
+	}
+}
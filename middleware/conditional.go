@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// CartETag formats a cart version as a strong entity tag
+func CartETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// CartRepresentationETag tags a rendered cart by its version and a hash of body.
+// Repricing, stock changes and query parameters change the body but not the
+// version, so If-None-Match must not match on the version alone. If-Match
+// still only compares the version part.
+func CartRepresentationETag(version int64, body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + strconv.FormatInt(version, 10) + "-" + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// IfMatch turns an If-Match header on mutating requests into a cart version
+// precondition on the request context (see domain.WithExpectedVersions).
+// The repository checks it atomically with the mutation, and a mismatch surfaces
+// as 412 Precondition Failed. "If-Match: *" and safe methods are not constrained.
+// Weak or malformed tags can never match, so they always fail the precondition.
+func IfMatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("If-Match")
+		if header == "" || strings.TrimSpace(header) == "*" || isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		versions := []int64{}
+		for _, tag := range strings.Split(header, ",") {
+			if version, ok := parseCartETag(strings.TrimSpace(tag)); ok {
+				versions = append(versions, version)
+			}
+		}
+		ctx := domain.WithExpectedVersions(c.Request.Context(), versions...)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// NoneMatch reports whether an If-None-Match header value matches etag using
+// the weak comparison RFC 9110 prescribes for If-None-Match
+func NoneMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCartETag parses a strong tag produced by CartETag or CartRepresentationETag
+// and returns its version
+func parseCartETag(tag string) (int64, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	opaque := tag[1 : len(tag)-1]
+	if i := strings.IndexByte(opaque, '-'); i >= 0 {
+		opaque = opaque[:i]
+	}
+	version, err := strconv.ParseInt(opaque, 10, 64)
+	if err != nil || version < 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+func TestIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		method  string
+		header  string
+		version int64
+		want    bool
+	}{
+		{"No header", http.MethodPatch, "", 7, true},
+		{"Wildcard", http.MethodPatch, "*", 7, true},
+		{"Matching tag", http.MethodPatch, `"7"`, 7, true},
+		{"Representation tag", http.MethodPatch, `"7-0123456789abcdef"`, 7, true},
+		{"Stale representation tag", http.MethodPatch, `"6-0123456789abcdef"`, 7, false},
+		{"Tag list", http.MethodDelete, `"3", "7"`, 7, true},
+		{"Stale tag", http.MethodPatch, `"6"`, 7, false},
+		{"Weak tag never matches", http.MethodPatch, `W/"7"`, 7, false},
+		{"Malformed tag", http.MethodPost, `7`, 7, false},
+		{"Safe method ignored", http.MethodGet, `"6"`, 7, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bool
+			r := gin.New()
+			r.Use(IfMatch())
+			r.Handle(tt.method, "/cart", func(c *gin.Context) {
+				got = domain.VersionMatches(c.Request.Context(), tt.version)
+			})
+
+			req := httptest.NewRequest(tt.method, "/cart", nil)
+			if tt.header != "" {
+				req.Header.Set("If-Match", tt.header)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("VersionMatches(%d) with If-Match %q = %v, want %v", tt.version, tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoneMatch(t *testing.T) {
+	etag := CartETag(5)
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`"5"`, true},
+		{`W/"5"`, true},
+		{`"4", "5"`, true},
+		{`*`, true},
+		{`"4"`, false},
+	}
+	for _, tt := range tests {
+		if got := NoneMatch(tt.header, etag); got != tt.want {
+			t.Errorf("NoneMatch(%q, %s) = %v, want %v", tt.header, etag, got, tt.want)
+		}
+	}
+}
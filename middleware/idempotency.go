@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/duynhne/pkg/logger/clog"
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client-chosen idempotency key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Problem codes returned by Idempotency
+const (
+	CodeInvalidIdempotencyKey    = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyReused     = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInProgress = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	CodeRequestTooLarge          = "REQUEST_TOO_LARGE"
+)
+
+const (
+	maxIdempotencyKeyLength = 255
+	maxIdempotentBodySize   = 1 << 20
+)
+
+// replayedHeaders are the response headers stored and replayed with the body
+var replayedHeaders = []string{"Content-Type", "ETag", "Cache-Control", "Location"}
+
+// Idempotency makes POST, PATCH and DELETE requests carrying an Idempotency-Key
+// safe to retry. The first request with a key runs normally and its response is
+// stored for ttl; a retry with the same key, method, path and body replays it
+// with "Idempotent-Replayed: true". Reusing a key for a different request is
+// rejected with 422, and a retry while the original is still running with 409.
+// Responses with a 5xx status are not stored, so the request can be retried.
+// Must run after AuthMiddleware: keys are scoped to the cart owner.
+func Idempotency(repo domain.IdempotencyRepository, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isIdempotencyMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			AbortWithProblem(c, http.StatusBadRequest, CodeInvalidIdempotencyKey, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+		ctx := c.Request.Context()
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBodySize+1))
+		if err != nil {
+			AbortWithProblem(c, http.StatusBadRequest, CodeInvalidIdempotencyKey, "Request body could not be read")
+			return
+		}
+		if len(body) > maxIdempotentBodySize {
+			AbortWithProblem(c, http.StatusRequestEntityTooLarge, CodeRequestTooLarge, "Request body is too large for an idempotent request")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		rec := &domain.IdempotencyRecord{
+			OwnerID:     c.GetString("user_id"),
+			Key:         key,
+			Fingerprint: requestFingerprint(c.Request.Method, c.Request.URL.Path, body),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+		existing, reserved, err := repo.Reserve(ctx, rec)
+		if err != nil {
+			clog.ErrorContext(ctx, "Failed to reserve idempotency key", "error", err)
+			AbortWithProblem(c, http.StatusInternalServerError, CodeInternal, "Internal server error")
+			return
+		}
+		if !reserved {
+			replayIdempotent(c, rec, existing)
+			return
+		}
+
+		// Stored or released even if the client has gone away, so its retry is answered
+		ctx = context.WithoutCancel(ctx)
+		release := func() {
+			if err := repo.Release(ctx, rec.OwnerID, rec.Key); err != nil {
+				clog.ErrorContext(ctx, "Failed to release idempotency key", "error", err)
+			}
+		}
+		// A panicking handler must not leave the key reserved until it expires;
+		// the recovery middleware still answers the request
+		defer func() {
+			if p := recover(); p != nil {
+				release()
+				panic(p)
+			}
+		}()
+
+		w := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			release()
+			return
+		}
+
+		rec.StatusCode = status
+		rec.Body = w.body.Bytes()
+		rec.Headers = make(map[string]string, len(replayedHeaders))
+		for _, name := range replayedHeaders {
+			if v := c.Writer.Header().Get(name); v != "" {
+				rec.Headers[name] = v
+			}
+		}
+		// The response is already sent; a failed store only loses the replay
+		if err := repo.Complete(ctx, rec); err != nil {
+			clog.ErrorContext(ctx, "Failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// replayIdempotent answers a request whose key is already taken by existing
+func replayIdempotent(c *gin.Context, rec, existing *domain.IdempotencyRecord) {
+	switch {
+	case existing.Fingerprint != rec.Fingerprint:
+		AbortWithProblem(c, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused,
+			"Idempotency-Key was already used for a different request")
+	case !existing.Completed():
+		c.Header("Retry-After", "1")
+		AbortWithProblem(c, http.StatusConflict, CodeIdempotencyKeyInProgress,
+			"A request with this Idempotency-Key is still being processed")
+	default:
+		for name, v := range existing.Headers {
+			c.Header(name, v)
+		}
+		c.Header("Idempotent-Replayed", "true")
+		c.Status(existing.StatusCode)
+		_, _ = c.Writer.Write(existing.Body)
+		c.Abort()
+	}
+}
+
+// requestFingerprint identifies a request by method, path and body
+func requestFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isIdempotencyMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPatch || method == http.MethodDelete
+}
+
+// capturingWriter copies the response body while writing it to the client
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/duynhne/cart-service/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// memoryIdempotencyRepository is an in-memory domain.IdempotencyRepository
+type memoryIdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+func newMemoryIdempotencyRepository() *memoryIdempotencyRepository {
+	return &memoryIdempotencyRepository{records: map[string]domain.IdempotencyRecord{}}
+}
+
+func (m *memoryIdempotencyRepository) Reserve(_ context.Context, rec *domain.IdempotencyRecord) (*domain.IdempotencyRecord, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := rec.OwnerID + "/" + rec.Key
+	if existing, ok := m.records[id]; ok && existing.ExpiresAt.After(time.Now()) {
+		return &existing, false, nil
+	}
+	m.records[id] = *rec
+	return nil, true, nil
+}
+
+func (m *memoryIdempotencyRepository) Complete(_ context.Context, rec *domain.IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[rec.OwnerID+"/"+rec.Key] = *rec
+	return nil
+}
+
+func (m *memoryIdempotencyRepository) Release(_ context.Context, ownerID, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, ownerID+"/"+key)
+	return nil
+}
+
+func (m *memoryIdempotencyRepository) DeleteExpired(context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMemoryIdempotencyRepository()
+	calls := 0
+	status := http.StatusOK
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", "42") })
+	r.Use(Idempotency(repo, time.Hour))
+	r.POST("/cart", func(c *gin.Context) {
+		calls++
+		c.Header("ETag", `"7"`)
+		c.JSON(status, gin.H{"calls": calls})
+	})
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/cart", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send("k1", `{"product_id":"p1"}`)
+	retry := send("k1", `{"product_id":"p1"}`)
+	if calls != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls)
+	}
+	if retry.Code != first.Code || retry.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", retry.Code, retry.Body, first.Code, first.Body)
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" || retry.Header().Get("ETag") != `"7"` {
+		t.Errorf("replay headers = %v, want Idempotent-Replayed and ETag", retry.Header())
+	}
+
+	if w := send("k1", `{"product_id":"p2"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("key reuse with another body status = %d, want 422", w.Code)
+	}
+
+	if _, _, err := repo.Reserve(context.Background(), &domain.IdempotencyRecord{
+		OwnerID: "42", Key: "busy", Fingerprint: requestFingerprint(http.MethodPost, "/cart", nil), ExpiresAt: time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if w := send("busy", ""); w.Code != http.StatusConflict {
+		t.Errorf("key in progress status = %d, want 409", w.Code)
+	}
+
+	status = http.StatusServiceUnavailable
+	send("k2", `{}`)
+	send("k2", `{}`)
+	if calls != 3 {
+		t.Errorf("handler calls after 5xx retry = %d, want 3 (5xx responses are not stored)", calls)
+	}
+
+	status = http.StatusOK
+	send("", `{}`)
+	send("", `{}`)
+	if calls != 5 {
+		t.Errorf("handler calls without key = %d, want 5", calls)
+	}
+}
+
+func TestIdempotencyPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMemoryIdempotencyRepository()
+	calls := 0
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(func(c *gin.Context) { c.Set("user_id", "42") })
+	r.Use(Idempotency(repo, time.Hour))
+	r.POST("/cart", func(c *gin.Context) {
+		calls++
+		panic("boom")
+	})
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/cart", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("status = %d, want 500", w.Code)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler calls = %d, want 2 (a panic releases the key)", calls)
+	}
+}
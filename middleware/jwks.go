@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/duynhne/pkg/logger/clog"
+)
+
+// ErrAuthUnavailable indicates tokens cannot be verified right now (e.g. no signing keys)
+var ErrAuthUnavailable = errors.New("authentication unavailable")
+
+// jwksMinRefreshInterval limits on-demand refreshes triggered by unknown key IDs,
+// so garbage tokens cannot be used to hammer the auth service.
+const jwksMinRefreshInterval = 30 * time.Second
+
+// jwk is a single JSON Web Key (RFC 7517). Only public key members are read.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// signingKey is a parsed verification key with the algorithm it may be used with
+type signingKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// JWKSCache holds the auth service's signing keys in memory.
+// Keys are refreshed in the background by Run and on demand when a token names
+// an unknown key ID, which picks up rotated keys before the next scheduled refresh.
+// Keys removed from the document are dropped on refresh.
+type JWKSCache struct {
+	url        string
+	httpClient *http.Client
+	interval   time.Duration
+
+	mu   sync.RWMutex
+	keys map[string]signingKey
+
+	refreshMu   sync.Mutex
+	lastAttempt time.Time // Start of the last fetch, successful or not; guarded by refreshMu
+}
+
+// NewJWKSCache creates a cache for the JWKS document at url, refreshed every interval
+func NewJWKSCache(url string, interval time.Duration) *JWKSCache {
+	return &JWKSCache{
+		url:        url,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		interval:   interval,
+		keys:       map[string]signingKey{},
+	}
+}
+
+// Run refreshes the keys every interval until ctx is cancelled.
+// Refresh failures keep the previous keys.
+func (j *JWKSCache) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.Refresh(ctx); err != nil {
+				clog.WarnContext(ctx, "JWKS refresh failed", "error", err)
+			}
+		}
+	}
+}
+
+// Refresh fetches the JWKS document and replaces the cached keys
+func (j *JWKSCache) Refresh(ctx context.Context) error {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+	return j.refresh(ctx)
+}
+
+func (j *JWKSCache) refresh(ctx context.Context) error {
+	j.lastAttempt = time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return fmt.Errorf("create JWKS request: %w", err)
+	}
+	resp, err := j.httpClient.Do(req) // #nosec G704 -- URL comes from operator config
+	if err != nil {
+		return fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("fetch JWKS: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&doc); err != nil {
+		return fmt.Errorf("decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]signingKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		parsed, err := k.parse()
+		if err != nil {
+			clog.WarnContext(ctx, "Skipping unusable JWK", "kid", k.Kid, "error", err)
+			continue
+		}
+		keys[k.Kid] = parsed
+	}
+	if len(keys) == 0 {
+		return errors.New("JWKS contains no usable signing keys")
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.mu.Unlock()
+	return nil
+}
+
+// key returns the signing key for kid, refreshing once if it is not cached
+func (j *JWKSCache) key(ctx context.Context, kid string) (signingKey, error) {
+	j.mu.RLock()
+	k, ok := j.keys[kid]
+	empty := len(j.keys) == 0
+	j.mu.RUnlock()
+	if ok {
+		return k, nil
+	}
+
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+
+	// Another request may have refreshed while we waited
+	j.mu.RLock()
+	k, ok = j.keys[kid]
+	empty = len(j.keys) == 0
+	j.mu.RUnlock()
+	if ok {
+		return k, nil
+	}
+	// Failed attempts count too, so an outage does not queue a fetch per request
+	if time.Since(j.lastAttempt) < jwksMinRefreshInterval {
+		if empty {
+			return signingKey{}, fmt.Errorf("%w: no signing keys loaded", ErrAuthUnavailable)
+		}
+		return signingKey{}, fmt.Errorf("unknown key id %q: %w", kid, ErrInvalidToken)
+	}
+
+	if err := j.refresh(ctx); err != nil {
+		if empty {
+			return signingKey{}, fmt.Errorf("%w: %w", ErrAuthUnavailable, err)
+		}
+		clog.WarnContext(ctx, "JWKS refresh failed", "error", err)
+	}
+
+	j.mu.RLock()
+	k, ok = j.keys[kid]
+	j.mu.RUnlock()
+	if !ok {
+		return signingKey{}, fmt.Errorf("unknown key id %q: %w", kid, ErrInvalidToken)
+	}
+	return k, nil
+}
+
+// parse converts the JWK into a public key and infers its algorithm
+func (k jwk) parse() (signingKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return signingKey{}, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return signingKey{}, errors.New("invalid RSA exponent")
+		}
+		if n.BitLen() < 2048 {
+			return signingKey{}, errors.New("RSA key shorter than 2048 bits")
+		}
+		return k.withAlg("RS256", &rsa.PublicKey{N: n, E: int(e.Int64())})
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return signingKey{}, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != 32 {
+			return signingKey{}, errors.New("invalid EC x coordinate")
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil || len(y) != 32 {
+			return signingKey{}, errors.New("invalid EC y coordinate")
+		}
+		// Rejects points that are not on the curve
+		pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(append([]byte{4}, x...), y...))
+		if err != nil {
+			return signingKey{}, err
+		}
+		return k.withAlg("ES256", pub)
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return signingKey{}, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return signingKey{}, errors.New("invalid Ed25519 key")
+		}
+		return k.withAlg("EdDSA", ed25519.PublicKey(x))
+
+	default:
+		return signingKey{}, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// withAlg rejects keys whose declared alg does not match their key type
+func (k jwk) withAlg(alg string, key crypto.PublicKey) (signingKey, error) {
+	if k.Alg != "" && k.Alg != alg {
+		return signingKey{}, fmt.Errorf("alg %q does not match key type %s", k.Alg, k.Kty)
+	}
+	return signingKey{alg: alg, key: key}, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Authenticator resolves a bearer token to the user it was issued to.
+// Implementations return an error wrapping ErrInvalidToken for tokens that
+// must be rejected; any other error is treated as an auth outage.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*AuthUser, error)
+}
+
+// ReadinessChecker is implemented by authenticators whose dependency health
+// should be reflected in /ready
+type ReadinessChecker interface {
+	Ready() bool
+}
+
+// jwtLeeway tolerates clock skew between cart and auth service when checking exp and nbf
+const jwtLeeway = 30 * time.Second
+
+// JWTVerifier validates auth service access tokens locally against its JWKS.
+// Supported algorithms are RS256, ES256 and EdDSA (Ed25519); the algorithm in the
+// token header must match the key it names, so "none" and HMAC tokens are rejected.
+type JWTVerifier struct {
+	keys     *JWKSCache
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// NewJWTVerifier creates a verifier that requires the given issuer and audience
+func NewJWTVerifier(keys *JWKSCache, issuer, audience string) *JWTVerifier {
+	return &JWTVerifier{keys: keys, issuer: issuer, audience: audience, now: time.Now}
+}
+
+// jwtHeader is the JOSE header of a compact JWS
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// jwtClaims are the registered and auth service claims read from the token
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Username  string   `json:"preferred_username"`
+	Email     string   `json:"email"`
+}
+
+// audience accepts both the string and the array form of "aud"
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// Authenticate implements Authenticator.
+func (v *JWTVerifier) Authenticate(ctx context.Context, token string) (*AuthUser, error) {
+	claims, err := v.verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthUser{ID: claims.Subject, Username: claims.Username, Email: claims.Email}, nil
+}
+
+// verify checks the token signature and claims and returns the claims
+func (v *JWTVerifier) verify(ctx context.Context, token string) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token: %w", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("token header: %w", ErrInvalidToken)
+	}
+
+	key, err := v.keys.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if header.Alg != key.alg {
+		return nil, fmt.Errorf("alg %q not allowed for key %q: %w", header.Alg, header.Kid, ErrInvalidToken)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("token signature: %w", ErrInvalidToken)
+	}
+	if !verifySignature(key, []byte(parts[0]+"."+parts[1]), sig) {
+		return nil, fmt.Errorf("bad signature: %w", ErrInvalidToken)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("token claims: %w", ErrInvalidToken)
+	}
+	if err := v.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func (v *JWTVerifier) validateClaims(c *jwtClaims) error {
+	now := v.now()
+	switch {
+	case c.ExpiresAt == nil:
+		return fmt.Errorf("missing exp: %w", ErrInvalidToken)
+	case now.After(time.Unix(*c.ExpiresAt, 0).Add(jwtLeeway)):
+		return fmt.Errorf("token expired: %w", ErrInvalidToken)
+	case c.NotBefore != nil && now.Add(jwtLeeway).Before(time.Unix(*c.NotBefore, 0)):
+		return fmt.Errorf("token not yet valid: %w", ErrInvalidToken)
+	case c.Issuer != v.issuer:
+		return fmt.Errorf("unexpected issuer %q: %w", c.Issuer, ErrInvalidToken)
+	case !slices.Contains(c.Audience, v.audience):
+		return fmt.Errorf("audience %q not allowed: %w", v.audience, ErrInvalidToken)
+	case c.Subject == "":
+		return fmt.Errorf("missing sub: %w", ErrInvalidToken)
+	}
+	return nil
+}
+
+// verifySignature checks a JWS signature over signingInput with the key's algorithm
+func verifySignature(key signingKey, signingInput, sig []byte) bool {
+	switch key.alg {
+	case "RS256":
+		pub, ok := key.key.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		digest := sha256.Sum256(signingInput)
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil
+	case "ES256":
+		pub, ok := key.key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return false
+		}
+		// JWS uses the fixed-size r||s encoding, not ASN.1
+		digest := sha256.Sum256(signingInput)
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pub, digest[:], r, s)
+	case "EdDSA":
+		pub, ok := key.key.(ed25519.PublicKey)
+		return ok && ed25519.Verify(pub, signingInput, sig)
+	default:
+		return false
+	}
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
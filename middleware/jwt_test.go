@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testIssuer = "https://auth.test"
+
+// testSigner signs tokens and publishes its public key as a JWK
+type testSigner struct {
+	kid string
+	alg string
+	key crypto.Signer
+}
+
+func (s testSigner) jwk() map[string]string {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch pub := s.key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{"kty": "RSA", "kid": s.kid, "n": b64(pub.N.Bytes()), "e": b64(big.NewInt(int64(pub.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		raw, _ := pub.Bytes()
+		return map[string]string{"kty": "EC", "kid": s.kid, "crv": "P-256", "x": b64(raw[1:33]), "y": b64(raw[33:])}
+	case ed25519.PublicKey:
+		return map[string]string{"kty": "OKP", "kid": s.kid, "crv": "Ed25519", "x": b64(pub)}
+	}
+	return nil
+}
+
+func (s testSigner) sign(t *testing.T, alg string, claims map[string]any) string {
+	t.Helper()
+	b64 := base64.RawURLEncoding.EncodeToString
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": s.kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := b64(header) + "." + b64(payload)
+
+	var sig []byte
+	var err error
+	switch key := s.key.(type) {
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(input))
+		sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(input))
+		var r, sVal *big.Int
+		r, sVal, err = ecdsa.Sign(rand.Reader, key, digest[:])
+		sig = append(r.FillBytes(make([]byte, 32)), sVal.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(key, []byte(input))
+	}
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return input + "." + b64(sig)
+}
+
+// jwksServer serves the JWK set of the current signers, which tests may replace
+type jwksServer struct {
+	mu      sync.Mutex
+	signers []testSigner
+	hits    int
+}
+
+func (j *jwksServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.hits++
+	keys := make([]map[string]string, 0, len(j.signers))
+	for _, s := range j.signers {
+		keys = append(keys, s.jwk())
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub": "42", "iss": testIssuer, "aud": []string{"private"},
+		"exp": time.Now().Add(time.Hour).Unix(), "preferred_username": "bob",
+	}
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	rs := testSigner{kid: "rs", alg: "RS256", key: rsaKey}
+	es := testSigner{kid: "es", alg: "ES256", key: ecKey}
+	ed := testSigner{kid: "ed", alg: "EdDSA", key: edKey}
+
+	jwks := &jwksServer{signers: []testSigner{rs, es, ed}}
+	srv := httptest.NewServer(jwks)
+	defer srv.Close()
+
+	verifier := NewJWTVerifier(NewJWKSCache(srv.URL, time.Hour), testIssuer, "private")
+
+	with := func(key string, value any) map[string]any {
+		c := validClaims()
+		if value == nil {
+			delete(c, key)
+		} else {
+			c[key] = value
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"RS256", rs.sign(t, "RS256", validClaims()), false},
+		{"ES256", es.sign(t, "ES256", validClaims()), false},
+		{"EdDSA", ed.sign(t, "EdDSA", validClaims()), false},
+		{"String audience", rs.sign(t, "RS256", with("aud", "private")), false},
+		{"Expired", rs.sign(t, "RS256", with("exp", time.Now().Add(-time.Hour).Unix())), true},
+		{"Missing exp", rs.sign(t, "RS256", with("exp", nil)), true},
+		{"Not yet valid", rs.sign(t, "RS256", with("nbf", time.Now().Add(time.Hour).Unix())), true},
+		{"Wrong audience", rs.sign(t, "RS256", with("aud", "public")), true},
+		{"Wrong issuer", rs.sign(t, "RS256", with("iss", "https://evil.test")), true},
+		{"Alg mismatch", rs.sign(t, "HS256", validClaims()), true},
+		{"Malformed", "not.a-token", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := verifier.Authenticate(context.Background(), tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("error = %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if user.ID != "42" || user.Username != "bob" {
+				t.Errorf("user = %+v, want id 42 / bob", user)
+			}
+		})
+	}
+
+	t.Run("Tampered payload", func(t *testing.T) {
+		// Payload of a token for user 1 with the signature of a token for user 42
+		genuine := strings.Split(rs.sign(t, "RS256", validClaims()), ".")
+		other := strings.Split(rs.sign(t, "RS256", with("sub", "1")), ".")
+		forged := genuine[0] + "." + other[1] + "." + genuine[2]
+		if _, err := verifier.Authenticate(context.Background(), forged); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("error = %v, want ErrInvalidToken", err)
+		}
+	})
+}
+
+func TestJWKSKeyRotation(t *testing.T) {
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+	oldSigner := testSigner{kid: "old", alg: "EdDSA", key: oldKey}
+	newSigner := testSigner{kid: "new", alg: "EdDSA", key: newKey}
+
+	jwks := &jwksServer{signers: []testSigner{oldSigner}}
+	srv := httptest.NewServer(jwks)
+	defer srv.Close()
+
+	cache := NewJWKSCache(srv.URL, time.Hour)
+	verifier := NewJWTVerifier(cache, testIssuer, "private")
+
+	if _, err := verifier.Authenticate(context.Background(), oldSigner.sign(t, "EdDSA", validClaims())); err != nil {
+		t.Fatalf("old key: %v", err)
+	}
+
+	// Rotate: the auth service now only publishes the new key
+	jwks.mu.Lock()
+	jwks.signers = []testSigner{newSigner}
+	jwks.mu.Unlock()
+	cache.refreshMu.Lock()
+	cache.lastAttempt = time.Time{} // allow the on-demand refresh immediately
+	cache.refreshMu.Unlock()
+
+	if _, err := verifier.Authenticate(context.Background(), newSigner.sign(t, "EdDSA", validClaims())); err != nil {
+		t.Fatalf("new key after rotation: %v", err)
+	}
+	if _, err := verifier.Authenticate(context.Background(), oldSigner.sign(t, "EdDSA", validClaims())); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("retired key error = %v, want ErrInvalidToken", err)
+	}
+
+	// Unknown key IDs do not trigger a refresh storm
+	hits := jwks.hits
+	for range 5 {
+		_, _ = verifier.Authenticate(context.Background(), testSigner{kid: "bogus", key: newKey}.sign(t, "EdDSA", validClaims()))
+	}
+	if jwks.hits != hits {
+		t.Errorf("JWKS fetched %d more times for unknown kids, want 0", jwks.hits-hits)
+	}
+}
+
+func TestJWKSUnavailable(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	verifier := NewJWTVerifier(NewJWKSCache(srv.URL, time.Hour), testIssuer, "private")
+
+	// Failed fetches are throttled like successful ones
+	for range 3 {
+		_, err := verifier.Authenticate(context.Background(), testSigner{kid: "k", key: key}.sign(t, "EdDSA", validClaims()))
+		if !errors.Is(err, ErrAuthUnavailable) || errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("error = %v, want ErrAuthUnavailable", err)
+		}
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("JWKS fetched %d times during the outage, want 1", n)
+	}
+}
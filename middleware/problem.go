@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/duynhne/pkg/logger/clog"
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// Stable problem codes used by the middleware; handlers define their own
+const (
+	CodeUnauthenticated  = "UNAUTHENTICATED"
+	CodeAuthUnavailable  = "AUTH_UNAVAILABLE"
+	CodeInternal         = "INTERNAL_ERROR"
+	CodeRouteNotFound    = "ROUTE_NOT_FOUND"
+	CodeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+)
+
+// Problem is an RFC 7807 problem details response body, extended with a stable
+// machine-readable code, the request trace ID and per-field validation errors.
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	Code          string         `json:"code"`
+	TraceID       string         `json:"trace_id,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam describes one request field that failed validation
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// WriteProblem stops the handler chain and writes p. Type, Title, Instance and
+// TraceID are filled from the status and request when left empty.
+func WriteProblem(c *gin.Context, p Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	if p.TraceID == "" {
+		p.TraceID = c.GetString("trace_id")
+	}
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// AbortWithProblem stops the handler chain and writes a problem details response
+func AbortWithProblem(c *gin.Context, status int, code, detail string) {
+	WriteProblem(c, Problem{Status: status, Code: code, Detail: detail})
+}
+
+// NoRouteProblem answers unknown routes with a 404 problem
+func NoRouteProblem(c *gin.Context) {
+	AbortWithProblem(c, http.StatusNotFound, CodeRouteNotFound, "No route matches "+c.Request.URL.Path)
+}
+
+// NoMethodProblem answers unsupported methods with a 405 problem
+func NoMethodProblem(c *gin.Context) {
+	AbortWithProblem(c, http.StatusMethodNotAllowed, CodeMethodNotAllowed, c.Request.Method+" is not allowed on "+c.Request.URL.Path)
+}
+
+// RecoverWithProblem is a gin.RecoveryFunc that reports panics as a 500 problem
+func RecoverWithProblem(c *gin.Context, recovered any) {
+	clog.ErrorContext(c.Request.Context(), "Panic recovered", "panic", recovered)
+	AbortWithProblem(c, http.StatusInternalServerError, CodeInternal, "Internal server error")
+}
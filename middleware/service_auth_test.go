@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServiceAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const secret = "0123456789abcdef0123456789abcdef"
+	r := gin.New()
+	internal := r.Group("/internal", ServiceAuth([]ServiceCaller{
+		{Name: "order", Secret: secret, Permissions: []string{"cart:clear"}},
+	}, 5*time.Minute))
+	internal.DELETE("/users/:userId/cart", RequireServicePermission("cart:clear"), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("service_name"))
+	})
+	internal.GET("/users/:userId/cart", RequireServicePermission("cart:read"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	now := time.Now()
+	tests := []struct {
+		name      string
+		method    string
+		caller    string
+		secret    string
+		timestamp time.Time
+		body      string
+		signBody  string
+		want      int
+	}{
+		{"signed", http.MethodDelete, "order", secret, now, `{"order_id":"1"}`, `{"order_id":"1"}`, http.StatusOK},
+		{"unknown caller", http.MethodDelete, "billing", secret, now, "", "", http.StatusUnauthorized},
+		{"wrong secret", http.MethodDelete, "order", strings.Repeat("x", 32), now, "", "", http.StatusUnauthorized},
+		{"stale timestamp", http.MethodDelete, "order", secret, now.Add(-10 * time.Minute), "", "", http.StatusUnauthorized},
+		{"tampered body", http.MethodDelete, "order", secret, now, `{"order_id":"2"}`, `{"order_id":"1"}`, http.StatusUnauthorized},
+		{"missing permission", http.MethodGet, "order", secret, now, "", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const path = "/internal/users/42/cart?region=EU"
+			req := httptest.NewRequest(tt.method, path, strings.NewReader(tt.body))
+			req.Header.Set(HeaderServiceName, tt.caller)
+			req.Header.Set(HeaderServiceTimestamp, strconv.FormatInt(tt.timestamp.Unix(), 10))
+			req.Header.Set(HeaderServiceSignature, SignServiceRequest(tt.secret, tt.timestamp, tt.method, path, []byte(tt.signBody)))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.want, w.Body.String())
+			}
+			if tt.want == http.StatusOK && w.Body.String() != "order" {
+				t.Errorf("service_name = %q, want order", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var authCacheLookups = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "auth_token_cache_lookups_total",
+		Help: "Introspection cache lookups by result (hit, negative_hit, miss)",
+	},
+	[]string{"result"},
+)
+
+// tokenKey is the SHA-256 of a bearer token; raw tokens are never kept in memory
+type tokenKey [sha256.Size]byte
+
+// tokenEntry is a cached introspection result. A nil user marks a negative entry.
+type tokenEntry struct {
+	key     tokenKey
+	user    *AuthUser
+	expires time.Time
+}
+
+// tokenCache is a bounded LRU of introspection results with per-entry expiry
+type tokenCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[tokenKey]*list.Element
+	order   *list.List // front = most recently used
+	now     func() time.Time
+}
+
+func newTokenCache(size int) *tokenCache {
+	return &tokenCache{
+		size:    size,
+		entries: make(map[tokenKey]*list.Element, size),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// get returns the cached entry for key if present and not expired
+func (c *tokenCache) get(key tokenKey) (tokenEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return tokenEntry{}, false
+	}
+	entry := el.Value.(*tokenEntry)
+	if !c.now().Before(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return tokenEntry{}, false
+	}
+	c.order.MoveToFront(el)
+	return *entry, true
+}
+
+// put stores user (nil for a negative entry) until expires, evicting the least recently used entry when full
+func (c *tokenCache) put(key tokenKey, user *AuthUser, expires time.Time) {
+	if !c.now().Before(expires) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value = &tokenEntry{key: key, user: user, expires: expires}
+		c.order.MoveToFront(el)
+		return
+	}
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*tokenEntry).key)
+	}
+	c.entries[key] = c.order.PushFront(&tokenEntry{key: key, user: user, expires: expires})
+}
+
+// tokenExpiry returns the "exp" claim when the token is a JWT. The signature is not
+// checked: the value is only used to cache an introspection result for less time.
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		ExpiresAt *int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ExpiresAt == nil {
+		return time.Time{}, false
+	}
+	return time.Unix(*claims.ExpiresAt, 0), true
+}
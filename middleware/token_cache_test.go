@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newTokenCache(2)
+	expires := time.Now().Add(time.Minute)
+	key := func(s string) tokenKey { return sha256.Sum256([]byte(s)) }
+
+	cache.put(key("a"), &AuthUser{ID: "a"}, expires)
+	cache.put(key("b"), &AuthUser{ID: "b"}, expires)
+	cache.get(key("a")) // a is now most recently used
+	cache.put(key("c"), &AuthUser{ID: "c"}, expires)
+
+	if _, ok := cache.get(key("b")); ok {
+		t.Error("b should have been evicted")
+	}
+	if _, ok := cache.get(key("a")); !ok {
+		t.Error("a should still be cached")
+	}
+}
+
+func TestAuthClientCache(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("Authorization") == "Bearer slow" {
+			<-release
+		}
+		if r.Header.Get("Authorization") == "Bearer bad" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "42"}`))
+	}))
+	defer auth.Close()
+
+	client := NewAuthClient(auth.URL, WithTokenCache(100, time.Minute, time.Minute))
+	ctx := context.Background()
+
+	t.Run("Positive", func(t *testing.T) {
+		calls.Store(0)
+		for range 3 {
+			if _, err := client.Authenticate(ctx, "good"); err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+		}
+		if calls.Load() != 1 {
+			t.Errorf("auth calls = %d, want 1", calls.Load())
+		}
+	})
+
+	t.Run("Negative", func(t *testing.T) {
+		calls.Store(0)
+		for range 3 {
+			if _, err := client.Authenticate(ctx, "bad"); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("error = %v, want ErrInvalidToken", err)
+			}
+		}
+		if calls.Load() != 1 {
+			t.Errorf("auth calls = %d, want 1", calls.Load())
+		}
+	})
+
+	t.Run("ExpiryCapsTTL", func(t *testing.T) {
+		calls.Store(0)
+		exp := time.Now().Add(-time.Second).Unix() // already expired: never cached
+		payload := base64.RawURLEncoding.EncodeToString([]byte(`{"exp":` + strconv.FormatInt(exp, 10) + `}`))
+		token := "e30." + payload + ".sig"
+		for range 2 {
+			_, _ = client.Authenticate(ctx, token)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("auth calls = %d, want 2", calls.Load())
+		}
+	})
+
+	t.Run("Singleflight", func(t *testing.T) {
+		calls.Store(0)
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = client.Authenticate(ctx, "slow")
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		if calls.Load() != 1 {
+			t.Errorf("auth calls = %d, want 1", calls.Load())
+		}
+	})
+}